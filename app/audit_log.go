@@ -0,0 +1,70 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	AuditRuleNoTarget = "no_target"
+	AuditRuleSurplus  = "surplus"
+
+	// AuditRuleDuplicate documents scans of unique equipment in several files which are not counted
+	AuditRuleDuplicate = "duplicate"
+)
+
+// AuditEvent documents how the actual count of an inventory row came about
+type AuditEvent struct {
+	ScanID   string       `json:"scan_id"`
+	Sources  []ScanOrigin `json:"sources"`
+	Line     int          `json:"line"`
+	OldValue string       `json:"old_value"`
+	NewValue string       `json:"new_value"`
+	Rule     string       `json:"rule"`
+}
+
+type AuditLog []AuditEvent
+
+func (a AuditLog) AsCSVContent() CSVContent {
+	content := CSVContent{{"Scan", "Quelle", "Zeile", "Alter Wert", "Neuer Wert", "Regel"}}
+
+	for _, event := range a {
+		var sources []string
+		for _, source := range event.Sources {
+			sources = append(sources, fmt.Sprintf("%s:%d", source.File, source.Line))
+		}
+
+		line := ""
+		if event.Line > 0 {
+			line = strconv.Itoa(event.Line)
+		}
+
+		content = append(content, []string{
+			event.ScanID,
+			strings.Join(sources, ", "),
+			line,
+			event.OldValue,
+			event.NewValue,
+			event.Rule,
+		})
+	}
+
+	return content
+}
+
+func (a AuditLog) WriteJSON(filePath string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log: %w", err)
+	}
+
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log '%s': %w", filePath, err)
+	}
+
+	return nil
+}
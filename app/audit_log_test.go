@@ -0,0 +1,69 @@
+package app_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditLog", func() {
+
+	var auditLog app.AuditLog
+
+	BeforeEach(func() {
+		auditLog = app.AuditLog{
+			{
+				ScanID:   "0591-s00001",
+				Sources:  []app.ScanOrigin{{File: "scanner1.csv", Line: 1}, {File: "scanner2.csv", Line: 4}},
+				Line:     2,
+				OldValue: "",
+				NewValue: "1",
+				Rule:     "fill_in_order",
+			},
+			{
+				ScanID:   "0591-s00001",
+				Sources:  []app.ScanOrigin{{File: "scanner1.csv", Line: 1}, {File: "scanner2.csv", Line: 4}},
+				NewValue: "1",
+				Rule:     "surplus",
+			},
+		}
+	})
+
+	var _ = Describe("AsCSVContent", func() {
+		It("returns the audit events as csv content", func() {
+			Expect(auditLog.AsCSVContent()).To(Equal(app.CSVContent{
+				{"Scan", "Quelle", "Zeile", "Alter Wert", "Neuer Wert", "Regel"},
+				{"0591-s00001", "scanner1.csv:1, scanner2.csv:4", "2", "", "1", "fill_in_order"},
+				{"0591-s00001", "scanner1.csv:1, scanner2.csv:4", "", "", "1", "surplus"},
+			}))
+		})
+	})
+
+	var _ = Describe("WriteJSON", func() {
+		It("writes the audit events as json", func() {
+			filePath := filepath.Join(os.TempDir(), "audit.json")
+			defer os.Remove(filePath)
+
+			err := auditLog.WriteJSON(filePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := os.ReadFile(filePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			var events []map[string]interface{}
+			err = json.Unmarshal(data, &events)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(events).To(HaveLen(2))
+			Expect(events[0]).To(HaveKeyWithValue("scan_id", "0591-s00001"))
+			Expect(events[0]).To(HaveKeyWithValue("line", BeEquivalentTo(2)))
+			Expect(events[0]).To(HaveKeyWithValue("new_value", "1"))
+			Expect(events[0]).To(HaveKeyWithValue("rule", "fill_in_order"))
+			Expect(events[0]["sources"]).To(HaveLen(2))
+		})
+	})
+})
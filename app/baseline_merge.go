@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+// BaselineMerge merges the counts of a new processing run into a previous result
+// without losing the counts which were changed manually in that result.
+type BaselineMerge interface {
+	// Counts extracts the equipment ID and the actual count of each row of a result
+	Counts(result CSVContent) (CSVContent, error)
+
+	// Merge keeps the manual counts of the baseline in the current result, rows are matched by
+	// equipment ID. A count is treated as manual value if the baseline differs from the previous
+	// counts, without previous counts (nil) every count of the baseline is treated as manual.
+	Merge(baseline CSVContent, previousCounts CSVContent, result CSVContent) (CSVContent, error)
+}
+
+type baselineMerge struct {
+	columns config.ConfigColumns
+	logger  utils.Logger
+}
+
+// NewBaselineMerge expects the columns resolved against the header of the inventory, see InventoryData.Columns
+func NewBaselineMerge(columns config.ConfigColumns, logger utils.Logger) BaselineMerge {
+	return &baselineMerge{
+		columns: columns,
+		logger:  logger,
+	}
+}
+
+func (b *baselineMerge) Counts(result CSVContent) (CSVContent, error) {
+	idIndex, actualIndex, err := b.columnIndexes(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts CSVContent
+	for _, record := range result {
+		counts = append(counts, []string{cell(record, idIndex), cell(record, actualIndex)})
+	}
+
+	return counts, nil
+}
+
+func (b *baselineMerge) Merge(baseline CSVContent, previousCounts CSVContent, result CSVContent) (CSVContent, error) {
+	baselineIDIndex, baselineActualIndex, err := b.columnIndexes(baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+
+	resultIDIndex, resultActualIndex, err := b.columnIndexes(result)
+	if err != nil {
+		return nil, err
+	}
+
+	baselineCounts := countsByID(baseline, baselineIDIndex, baselineActualIndex)
+
+	previous := make(map[string][]string)
+	if previousCounts != nil {
+		previousIDIndex, previousActualIndex, err := b.columnIndexes(previousCounts)
+		if err != nil {
+			return nil, fmt.Errorf("previous counts: %w", err)
+		}
+		previous = countsByID(previousCounts, previousIDIndex, previousActualIndex)
+	}
+
+	conflicts := ReportTable{
+		Title: "manually changed counts conflicting with recorded equipment:",
+		Columns: []ReportColumn{
+			{Header: "line", AlignRight: true},
+			{Header: "equipment"},
+			{Header: "manual", AlignRight: true},
+			{Header: "recorded", AlignRight: true},
+		},
+		Warning: true,
+	}
+
+	merged := CSVContent{result[0]}
+	occurrences := make(map[string]int)
+
+	for i := 1; i < len(result); i++ {
+		row := append([]string{}, result[i]...)
+		for len(row) <= resultActualIndex {
+			row = append(row, "")
+		}
+
+		// rows with the same ID are matched in order, rows without ID are not counted
+		id := cell(row, resultIDIndex)
+		occurrence := occurrences[id]
+		occurrences[id]++
+
+		if id == "" || occurrence >= len(baselineCounts[id]) {
+			merged = append(merged, row)
+			continue
+		}
+
+		baselineValue := baselineCounts[id][occurrence]
+		currentValue := row[resultActualIndex]
+
+		// without previous counts every value of the baseline is treated as manual
+		previousValue := ""
+		if occurrence < len(previous[id]) {
+			previousValue = previous[id][occurrence]
+		}
+
+		if baselineValue != previousValue {
+			row[resultActualIndex] = baselineValue
+			if currentValue != previousValue && currentValue != baselineValue {
+				conflicts.Rows = append(conflicts.Rows, []string{strconv.Itoa(i + 1), id, baselineValue, currentValue})
+			}
+		}
+
+		merged = append(merged, row)
+	}
+
+	removed := ReportTable{
+		Title:   "counted rows of the baseline not in the inventory anymore:",
+		Columns: []ReportColumn{{Header: "line", AlignRight: true}, {Header: "equipment"}, {Header: "count", AlignRight: true}},
+		Warning: true,
+	}
+	baselineOccurrences := make(map[string]int)
+	for i := 1; i < len(baseline); i++ {
+		id := cell(baseline[i], baselineIDIndex)
+		occurrence := baselineOccurrences[id]
+		baselineOccurrences[id]++
+
+		value := cell(baseline[i], baselineActualIndex)
+		if id != "" && occurrence >= occurrences[id] && value != "" {
+			removed.Rows = append(removed.Rows, []string{strconv.Itoa(i + 1), id, value})
+		}
+	}
+
+	reporter := NewReporter(b.logger)
+	reporter.Table(conflicts)
+	reporter.Table(removed)
+
+	return merged, nil
+}
+
+// countsByID returns the actual counts per equipment ID in the order of the rows
+func countsByID(content CSVContent, idIndex int, actualIndex int) map[string][]string {
+	counts := make(map[string][]string)
+	for i := 1; i < len(content); i++ {
+		id := cell(content[i], idIndex)
+		counts[id] = append(counts[id], cell(content[i], actualIndex))
+	}
+	return counts
+}
+
+func (b *baselineMerge) columnIndexes(content CSVContent) (int, int, error) {
+	if len(content) == 0 {
+		return 0, 0, fmt.Errorf("content is empty")
+	}
+
+	// only the ID and the actual count are required
+	idIndex := indexOf(content[0], b.columns.EquipmentID)
+	actualIndex := indexOf(content[0], b.columns.EquipmentCountActual)
+
+	if idIndex < 0 {
+		return 0, 0, fmt.Errorf("column '%s' is missing", b.columns.EquipmentID)
+	}
+	if actualIndex < 0 {
+		return 0, 0, fmt.Errorf("column '%s' is missing", b.columns.EquipmentCountActual)
+	}
+
+	return idIndex, actualIndex, nil
+}
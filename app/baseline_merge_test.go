@@ -0,0 +1,166 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BaselineMerge", func() {
+
+	var (
+		logger        *utilsfakes.FakeLogger
+		baselineMerge app.BaselineMerge
+	)
+
+	BeforeEach(func() {
+		logger = &utilsfakes.FakeLogger{}
+		baselineMerge = app.NewBaselineMerge(config.ConfigColumns{
+			EquipmentID:          "Inventar Nr",
+			EquipmentCountActual: "Bestand IST",
+		}, logger)
+	})
+
+	var _ = Describe("Counts", func() {
+		It("returns the equipment IDs and actual counts", func() {
+			counts, err := baselineMerge.Counts(app.CSVContent{
+				{"Ausstattung", "Inventar Nr", "Bestand IST"},
+				{"Handlampe", "0591-S00001", "1"},
+				{"Fuchsschwanz", "0591-S00002"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(counts).To(Equal(app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "1"},
+				{"0591-S00002", ""},
+			}))
+		})
+
+		It("returns an error if the actual column is missing", func() {
+			_, err := baselineMerge.Counts(app.CSVContent{
+				{"Ausstattung", "Inventar Nr"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("column 'Bestand IST' is missing"))
+		})
+	})
+
+	var _ = Describe("Merge", func() {
+		It("keeps manual values and applies the current counts", func() {
+			baseline := app.CSVContent{
+				{"Ausstattung", "Inventar Nr", "Bestand IST"},
+				{"Handlampe", "0591-S00001", "1"},
+				{"Fuchsschwanz", "0591-S00002", "5"},
+				{"Rettungsweste", "0591-S00003", ""},
+			}
+			previousCounts := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "1"},
+				{"0591-S00002", ""},
+				{"0591-S00003", ""},
+			}
+			result := app.CSVContent{
+				{"Ausstattung", "Inventar Nr", "Bestand IST"},
+				{"Handlampe", "0591-S00001", "2"},
+				{"Fuchsschwanz", "0591-S00002", ""},
+				{"Rettungsweste", "0591-S00003", "1"},
+			}
+
+			merged, err := baselineMerge.Merge(baseline, previousCounts, result)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged).To(Equal(app.CSVContent{
+				{"Ausstattung", "Inventar Nr", "Bestand IST"},
+				{"Handlampe", "0591-S00001", "2"},
+				{"Fuchsschwanz", "0591-S00002", "5"},
+				{"Rettungsweste", "0591-S00003", "1"},
+			}))
+			Expect(logger.WarnIndentedCallCount()).To(Equal(0))
+		})
+
+		It("reports manual values conflicting with the current counts", func() {
+			baseline := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "3"},
+			}
+			previousCounts := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "1"},
+			}
+			result := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "2"},
+			}
+
+			merged, err := baselineMerge.Merge(baseline, previousCounts, result)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged[1]).To(Equal([]string{"0591-S00001", "3"}))
+			Expect(logger.WarnIndentedCallCount()).To(Equal(3))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("   2 : 0591-S00001 :      3 :        2"))
+		})
+
+		It("matches the rows by equipment ID if the inventory gained or lost lines", func() {
+			baseline := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "3"},
+				{"0591-S00002", "1"},
+				{"0591-S00003", "4"},
+			}
+			previousCounts := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "1"},
+				{"0591-S00002", "1"},
+				{"0591-S00003", "1"},
+			}
+			result := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00004", "1"},
+				{"0591-S00001", "1"},
+				{"0591-S00003", "2"},
+			}
+
+			merged, err := baselineMerge.Merge(baseline, previousCounts, result)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged).To(Equal(app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00004", "1"},
+				{"0591-S00001", "3"},
+				{"0591-S00003", "4"},
+			}))
+
+			Expect(logger.WarnArgsForCall(0)).To(Equal("manually changed counts conflicting with recorded equipment:"))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("   4 : 0591-S00003 :      4 :        2"))
+			Expect(logger.WarnArgsForCall(3)).To(Equal("counted rows of the baseline not in the inventory anymore:"))
+			Expect(logger.WarnIndentedArgsForCall(5)).To(Equal("   3 : 0591-S00002 :     1"))
+		})
+
+		It("keeps the counts of the baseline without previous counts", func() {
+			baseline := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "3"},
+				{"0591-S00002", ""},
+			}
+			result := app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "3"},
+				{"0591-S00002", "1"},
+			}
+
+			merged, err := baselineMerge.Merge(baseline, nil, result)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(merged).To(Equal(app.CSVContent{
+				{"Inventar Nr", "Bestand IST"},
+				{"0591-S00001", "3"},
+				{"0591-S00002", "1"},
+			}))
+			Expect(logger.WarnCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// exit codes of the tool, documented in the usage and the README
+const (
+	ExitSuccess  = 0
+	ExitFailure  = 1
+	ExitWarnings = 2
+)
+
+// ExitCode returns the exit code for the result of the steps
+func ExitCode(err error, warnings int) int {
+	if err != nil {
+		return ExitFailure
+	}
+	if warnings > 0 {
+		return ExitWarnings
+	}
+	return ExitSuccess
+}
+
+// CommandLine is the parsed command line of the tool
+type CommandLine struct {
+	ConfigPath string
+	Steps      []Step
+	FlagValues map[string]string
+
+	// NoPause skips waiting for Enter before the tool exits
+	NoPause bool
+
+	// Help is set if the usage was requested and printed
+	Help bool
+}
+
+// ParseCommandLine parses the arguments without the program name, either as
+// subcommand like "process -b latest" or in the form "-s init,process"
+func ParseCommandLine(registry StepRegistry, program string, args []string, output io.Writer) (CommandLine, error) {
+	if len(args) > 0 && args[0] == "help" {
+		return printCommandHelp(registry, program, args[1:], output)
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		steps, err := registry.Resolve(args[0])
+		if err != nil {
+			return CommandLine{}, fmt.Errorf("unknown command '%s', run '%s help' for a list of commands", args[0], program)
+		}
+		return parseFlags(steps, stepFlags(steps), fmt.Sprintf("%s %s", program, args[0]), args[1:], output, func() {
+			printCommandUsage(program, args[0], steps, output)
+		})
+	}
+
+	var stepNames string
+	commandLine, err := parseFlags(nil, registry.Flags(), program, args, output, func() {
+		printUsage(registry, program, output)
+	}, func(flags *flag.FlagSet) {
+		flags.StringVar(&stepNames, "s", "process", "the inventory steps, several steps are separated by comma, e.g. init,process")
+	})
+	if err != nil || commandLine.Help {
+		return commandLine, err
+	}
+
+	commandLine.Steps, err = registry.Resolve(stepNames)
+	return commandLine, err
+}
+
+func parseFlags(steps []Step, stepFlags []StepFlag, name string, args []string, output io.Writer, usage func(), extraFlags ...func(flags *flag.FlagSet)) (CommandLine, error) {
+	commandLine := CommandLine{
+		Steps:      steps,
+		FlagValues: make(map[string]string),
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.Usage = usage
+
+	flags.StringVar(&commandLine.ConfigPath, "c", "config.json", "the config file path")
+	flags.BoolVar(&commandLine.NoPause, "no-pause", false, "exit without waiting for Enter")
+	for _, extraFlag := range extraFlags {
+		extraFlag(flags)
+	}
+
+	values := make(map[string]*string)
+	for _, stepFlag := range stepFlags {
+		values[stepFlag.Name] = flags.String(stepFlag.Name, "", stepFlag.Usage)
+	}
+
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		commandLine.Help = true
+		return commandLine, nil
+	}
+	if err != nil {
+		return commandLine, err
+	}
+
+	if flags.NArg() > 0 {
+		return commandLine, fmt.Errorf("unexpected argument '%s'", flags.Arg(0))
+	}
+
+	for name, value := range values {
+		commandLine.FlagValues[name] = *value
+	}
+
+	return commandLine, nil
+}
+
+// stepFlags returns the flags of the steps, each name once
+func stepFlags(steps []Step) []StepFlag {
+	registry := &stepRegistry{steps: steps}
+	return registry.Flags()
+}
+
+func printCommandHelp(registry StepRegistry, program string, args []string, output io.Writer) (CommandLine, error) {
+	if len(args) == 0 {
+		printUsage(registry, program, output)
+		return CommandLine{Help: true}, nil
+	}
+
+	steps, err := registry.Resolve(args[0])
+	if err != nil {
+		return CommandLine{}, fmt.Errorf("unknown command '%s', run '%s help' for a list of commands", args[0], program)
+	}
+
+	printCommandUsage(program, args[0], steps, output)
+	return CommandLine{Help: true}, nil
+}
+
+func printUsage(registry StepRegistry, program string, output io.Writer) {
+	fmt.Fprintf(output, "Usage: %s <command>[,<command>...] [flags]\n", program)
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Commands:")
+	for _, step := range registry.Steps() {
+		fmt.Fprintf(output, "  %-10s %s\n", step.Name(), step.Description())
+	}
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Common flags:")
+	printFlag(output, "c", "the config file path (default \"config.json\")")
+	printFlag(output, "no-pause", "exit without waiting for Enter")
+	fmt.Fprintln(output)
+	fmt.Fprintf(output, "Run '%s help <command>' for the flags of a command.\n", program)
+	fmt.Fprintf(output, "Without command the steps are given with -s, e.g. '%s -s init,process', the default is process.\n", program)
+	printExitCodes(output)
+}
+
+func printCommandUsage(program string, command string, steps []Step, output io.Writer) {
+	fmt.Fprintf(output, "Usage: %s %s [flags]\n", program, command)
+	fmt.Fprintln(output)
+	for _, step := range steps {
+		fmt.Fprintf(output, "  %-10s %s\n", step.Name(), step.Description())
+	}
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Flags:")
+	printFlag(output, "c", "the config file path (default \"config.json\")")
+	printFlag(output, "no-pause", "exit without waiting for Enter")
+	for _, stepFlag := range stepFlags(steps) {
+		printFlag(output, stepFlag.Name, stepFlag.Usage)
+	}
+	printExitCodes(output)
+}
+
+func printFlag(output io.Writer, name string, usage string) {
+	fmt.Fprintf(output, "  -%-10s %s\n", name, usage)
+}
+
+func printExitCodes(output io.Writer) {
+	fmt.Fprintln(output)
+	fmt.Fprintln(output, "Exit codes:")
+	fmt.Fprintf(output, "  %d  success\n", ExitSuccess)
+	fmt.Fprintf(output, "  %d  failure\n", ExitFailure)
+	fmt.Fprintf(output, "  %d  success with warnings\n", ExitWarnings)
+}
@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CommandLine", func() {
+
+	var (
+		registry app.StepRegistry
+		output   *bytes.Buffer
+	)
+
+	stepNames := func(steps []app.Step) []string {
+		var names []string
+		for _, step := range steps {
+			names = append(names, step.Name())
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+		registry = app.NewStepRegistry()
+
+		baselineFlag := app.StepFlag{
+			Name:  "b",
+			Usage: "the baseline result",
+			Apply: func(config *config.Config, value string) {
+				config.BaselineResult = value
+			},
+		}
+		noop := func(ctx app.StepContext) error { return nil }
+
+		Expect(registry.Register(app.NewStep("init", "init description", nil, noop))).To(Succeed())
+		Expect(registry.Register(app.NewStep("process", "process description", []app.StepFlag{baselineFlag}, noop))).To(Succeed())
+	})
+
+	It("should parse a subcommand with its flags", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", []string{"process", "-b", "latest", "--no-pause", "-c", "other.json"}, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stepNames(commandLine.Steps)).To(Equal([]string{"process"}))
+		Expect(commandLine.FlagValues).To(Equal(map[string]string{"b": "latest"}))
+		Expect(commandLine.ConfigPath).To(Equal("other.json"))
+		Expect(commandLine.NoPause).To(BeTrue())
+		Expect(commandLine.Help).To(BeFalse())
+	})
+
+	It("should parse chained subcommands", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", []string{"init,process"}, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stepNames(commandLine.Steps)).To(Equal([]string{"init", "process"}))
+		Expect(commandLine.ConfigPath).To(Equal("config.json"))
+		Expect(commandLine.NoPause).To(BeFalse())
+	})
+
+	It("should reject flags of other commands", func() {
+		_, err := app.ParseCommandLine(registry, "tool", []string{"init", "-b", "latest"}, output)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject unknown commands", func() {
+		_, err := app.ParseCommandLine(registry, "tool", []string{"unknown"}, output)
+		Expect(err).To(MatchError("unknown command 'unknown', run 'tool help' for a list of commands"))
+	})
+
+	It("should parse the steps given with -s", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", []string{"-s", "init,process", "-b", "latest"}, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stepNames(commandLine.Steps)).To(Equal([]string{"init", "process"}))
+		Expect(commandLine.FlagValues).To(Equal(map[string]string{"b": "latest"}))
+	})
+
+	It("should run the process step without arguments", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", nil, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stepNames(commandLine.Steps)).To(Equal([]string{"process"}))
+	})
+
+	It("should print the usage with the commands and exit codes", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", []string{"help"}, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(commandLine.Help).To(BeTrue())
+		Expect(output.String()).To(ContainSubstring("process    process description"))
+		Expect(output.String()).To(ContainSubstring("2  success with warnings"))
+	})
+
+	It("should print the flags of a command", func() {
+		commandLine, err := app.ParseCommandLine(registry, "tool", []string{"process", "-h"}, output)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(commandLine.Help).To(BeTrue())
+		Expect(output.String()).To(ContainSubstring("Usage: tool process [flags]"))
+		Expect(output.String()).To(ContainSubstring("-b          the baseline result"))
+	})
+
+	It("should return the exit code for the result", func() {
+		Expect(app.ExitCode(nil, 0)).To(Equal(app.ExitSuccess))
+		Expect(app.ExitCode(nil, 3)).To(Equal(app.ExitWarnings))
+		Expect(app.ExitCode(errors.New("failed"), 3)).To(Equal(app.ExitFailure))
+	})
+})
@@ -3,10 +3,15 @@ package app
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"thwInventoryMerge/utils"
 
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
@@ -19,12 +24,20 @@ type CSVFile interface {
 }
 
 type csvFile struct {
-	logger utils.Logger
+	encoding encoding.Encoding
+	logger   utils.Logger
 }
 
+// NewCSVFile returns a CSV file written in UTF-8 with BOM
 func NewCSVFile(logger utils.Logger) CSVFile {
+	return NewCSVFileWithEncoding(unicode.UTF8, logger)
+}
+
+// NewCSVFileWithEncoding returns a CSV file written in the encoding, a BOM is written for UTF-8 only
+func NewCSVFileWithEncoding(encoding encoding.Encoding, logger utils.Logger) CSVFile {
 	return &csvFile{
-		logger: logger,
+		encoding: encoding,
+		logger:   logger,
 	}
 }
 
@@ -43,6 +56,11 @@ func (c *csvFile) Read(filePath string, encoding encoding.Encoding) (CSVContent,
 		return nil, fmt.Errorf("failed to read CSV file '%s': %w", filePath, err)
 	}
 
+	// the BOM written by Write is no part of the first header
+	if len(content) > 0 && len(content[0]) > 0 {
+		content[0][0] = strings.TrimPrefix(content[0][0], "\ufeff")
+	}
+
 	return content, nil
 }
 
@@ -54,13 +72,22 @@ func (c *csvFile) Write(filePath string, content CSVContent) error {
 	}
 	defer file.Close()
 
-	// Write the UTF-8 BOM
-	_, err = file.Write([]byte{0xEF, 0xBB, 0xBF})
-	if err != nil {
-		return fmt.Errorf("failed to write UTF-8 BOM to CSV file: %w", err)
+	var output io.Writer = file
+	var encoder *transform.Writer
+
+	if c.encoding == unicode.UTF8 {
+		// Write the UTF-8 BOM
+		_, err = file.Write([]byte{0xEF, 0xBB, 0xBF})
+		if err != nil {
+			return fmt.Errorf("failed to write UTF-8 BOM to CSV file: %w", err)
+		}
+	} else {
+		content = c.replaceUnsupported(filePath, content)
+		encoder = transform.NewWriter(file, c.encoding.NewEncoder())
+		output = encoder
 	}
 
-	writer := csv.NewWriter(file)
+	writer := csv.NewWriter(output)
 	writer.Comma = ';'
 
 	err = writer.WriteAll(content)
@@ -69,5 +96,55 @@ func (c *csvFile) Write(filePath string, content CSVContent) error {
 	}
 
 	writer.Flush()
+
+	// closing the encoder flushes its buffer, the file itself is closed by the deferred call
+	if encoder != nil {
+		err = encoder.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write into CSV file: %w", err)
+		}
+	}
+
 	return nil
 }
+
+// replaceUnsupported replaces the characters which the encoding cannot represent by '?' and warns about them
+func (c *csvFile) replaceUnsupported(filePath string, content CSVContent) CSVContent {
+	table := ReportTable{
+		Title:   fmt.Sprintf("characters not representable in %s written as '?' to '%s':", c.encoding, filepath.Base(filePath)),
+		Columns: []ReportColumn{{Header: "line", AlignRight: true}, {Header: "characters"}, {Header: "value"}},
+		Warning: true,
+	}
+
+	var result CSVContent
+	for i, record := range content {
+		var replaced []string
+		var unsupported []rune
+
+		for _, value := range record {
+			if _, err := c.encoding.NewEncoder().String(value); err == nil {
+				replaced = append(replaced, value)
+				continue
+			}
+
+			var builder strings.Builder
+			for _, r := range value {
+				if _, err := c.encoding.NewEncoder().String(string(r)); err != nil {
+					unsupported = append(unsupported, r)
+					r = '?'
+				}
+				builder.WriteRune(r)
+			}
+			replaced = append(replaced, builder.String())
+		}
+
+		if len(unsupported) > 0 {
+			table.Rows = append(table.Rows, []string{strconv.Itoa(i + 1), string(unsupported), strings.Join(record, ";")})
+		}
+		result = append(result, replaced)
+	}
+
+	NewReporter(c.logger).Table(table)
+
+	return result
+}
@@ -239,6 +239,59 @@ var _ = Describe("CSVFile", func() {
 			Expect(bom).To(Equal([]byte{0xEF, 0xBB, 0xBF, 0x6E, 0x61, 0x6D, 0x65}))
 		})
 
+		It("should read the written header without the utf-8 bom", func() {
+			filePath = filepath.Join(os.TempDir(), "output.csv")
+
+			content = [][]string{
+				{"name", "age", "city"},
+			}
+
+			csvFile := app.NewCSVFile(logger)
+			err := csvFile.Write(filePath, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			readContent, err := csvFile.Read(filePath, unicode.UTF8)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readContent).To(Equal(app.CSVContent{{"name", "age", "city"}}))
+		})
+
+		It("should write iso-8859-1 without bom", func() {
+			filePath = filepath.Join(os.TempDir(), "output.csv")
+
+			content = [][]string{
+				{"Ausstattung", "Menge"},
+				{"Gerät", "1"},
+			}
+
+			err := app.NewCSVFileWithEncoding(charmap.ISO8859_1, logger).Write(filePath, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := os.ReadFile(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal([]byte("Ausstattung;Menge\nGer\xe4t;1\n")))
+			Expect(logger.WarnCallCount()).To(Equal(0))
+		})
+
+		It("should replace characters not representable in iso-8859-1 and warn about them", func() {
+			filePath = filepath.Join(os.TempDir(), "output.csv")
+
+			content = [][]string{
+				{"Ausstattung", "Menge"},
+				{"Leuchte 230V – LED", "1"},
+			}
+
+			csvFile := app.NewCSVFileWithEncoding(charmap.ISO8859_1, logger)
+			err := csvFile.Write(filePath, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			readContent, err := csvFile.Read(filePath, charmap.ISO8859_1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(readContent[1]).To(Equal([]string{"Leuchte 230V ? LED", "1"}))
+
+			Expect(logger.WarnArgsForCall(0)).To(Equal("characters not representable in ISO 8859-1 written as '?' to 'output.csv':"))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("   2 : –          : Leuchte 230V – LED;1"))
+		})
+
 		Context("when the file path is invalid", func() {
 			It("should return an error", func() {
 				invalidPath := "/invalid/output.csv" // Likely to be invalid on most systems
@@ -0,0 +1,21 @@
+package app
+
+import "thwInventoryMerge/utils"
+
+// indexOf compares the normalized headers, see utils.NormalizeHeader
+func indexOf(header []string, colName string) int {
+	for i, name := range header {
+		if utils.EqualHeaders(name, colName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// cell returns an empty string for a short record or a missing column
+func cell(record []string, index int) string {
+	if index >= 0 && index < len(record) {
+		return record[index]
+	}
+	return ""
+}
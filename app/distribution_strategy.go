@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"thwInventoryMerge/config"
+)
+
+// DistributionCandidate describes an inventory row matching a recorded equipment ID
+type DistributionCandidate struct {
+	Target    int
+	Unlimited bool
+	Layer     int
+}
+
+type DistributionStrategy interface {
+	Name() string
+
+	// Distribute spreads the amount across the candidates and returns the
+	// amount for each candidate and the surplus which did not fit anywhere
+	Distribute(amount int, candidates []DistributionCandidate) ([]int, int)
+}
+
+func NewDistributionStrategy(name string) (DistributionStrategy, error) {
+	switch name {
+	case "", config.CountDistributionFillInOrder:
+		return fillInOrderStrategy{}, nil
+	case config.CountDistributionProportional:
+		return proportionalStrategy{}, nil
+	case config.CountDistributionDeepestLayer:
+		return deepestLayerStrategy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown count distribution '%s'", name)
+	}
+}
+
+type fillInOrderStrategy struct{}
+
+func (s fillInOrderStrategy) Name() string {
+	return config.CountDistributionFillInOrder
+}
+
+func (s fillInOrderStrategy) Distribute(amount int, candidates []DistributionCandidate) ([]int, int) {
+	allocations := make([]int, len(candidates))
+
+	for i, candidate := range candidates {
+		if amount <= 0 {
+			break
+		}
+
+		if candidate.Unlimited {
+			allocations[i] = amount
+			amount = 0
+			break
+		}
+
+		allocations[i] = min(amount, max(candidate.Target, 0))
+		amount -= allocations[i]
+	}
+
+	return allocations, amount
+}
+
+type proportionalStrategy struct{}
+
+func (s proportionalStrategy) Name() string {
+	return config.CountDistributionProportional
+}
+
+func (s proportionalStrategy) Distribute(amount int, candidates []DistributionCandidate) ([]int, int) {
+	allocations := make([]int, len(candidates))
+
+	total := 0
+	for _, candidate := range candidates {
+		if !candidate.Unlimited {
+			total += max(candidate.Target, 0)
+		}
+	}
+
+	// everything fits, the remaining amount goes to the first unlimited row
+	if amount >= total {
+		for i, candidate := range candidates {
+			if !candidate.Unlimited {
+				allocations[i] = max(candidate.Target, 0)
+			}
+		}
+		amount -= total
+
+		for i, candidate := range candidates {
+			if candidate.Unlimited {
+				allocations[i] = amount
+				amount = 0
+				break
+			}
+		}
+
+		return allocations, amount
+	}
+
+	// largest remainder method
+	remainders := make([]int, len(candidates))
+	distributed := 0
+	for i, candidate := range candidates {
+		if candidate.Unlimited {
+			continue
+		}
+		share := amount * max(candidate.Target, 0)
+		allocations[i] = share / total
+		remainders[i] = share % total
+		distributed += allocations[i]
+	}
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+
+	for _, i := range order[:amount-distributed] {
+		allocations[i]++
+	}
+
+	return allocations, 0
+}
+
+type deepestLayerStrategy struct{}
+
+func (s deepestLayerStrategy) Name() string {
+	return config.CountDistributionDeepestLayer
+}
+
+func (s deepestLayerStrategy) Distribute(amount int, candidates []DistributionCandidate) ([]int, int) {
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return candidates[order[a]].Layer > candidates[order[b]].Layer
+	})
+
+	sorted := make([]DistributionCandidate, len(candidates))
+	for i, index := range order {
+		sorted[i] = candidates[index]
+	}
+
+	sortedAllocations, surplus := fillInOrderStrategy{}.Distribute(amount, sorted)
+
+	allocations := make([]int, len(candidates))
+	for i, index := range order {
+		allocations[index] = sortedAllocations[i]
+	}
+
+	return allocations, surplus
+}
@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DistributionStrategy", func() {
+
+	It("returns an error for an unknown strategy", func() {
+		_, err := app.NewDistributionStrategy("random")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("unknown count distribution 'random'"))
+	})
+
+	var _ = Describe("fill_in_order", func() {
+		var strategy app.DistributionStrategy
+
+		BeforeEach(func() {
+			var err error
+			strategy, err = app.NewDistributionStrategy("fill_in_order")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fills the candidates in order up to their target", func() {
+			allocations, surplus := strategy.Distribute(4, []app.DistributionCandidate{
+				{Target: 1}, {Target: 5}, {Target: 2},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 3, 0}))
+			Expect(surplus).To(Equal(0))
+		})
+
+		It("returns the amount exceeding all targets as surplus", func() {
+			allocations, surplus := strategy.Distribute(10, []app.DistributionCandidate{
+				{Target: 1}, {Target: 5},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 5}))
+			Expect(surplus).To(Equal(4))
+		})
+
+		It("puts the remaining amount on the first unlimited candidate", func() {
+			allocations, surplus := strategy.Distribute(10, []app.DistributionCandidate{
+				{Target: 1}, {Unlimited: true}, {Unlimited: true},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 9, 0}))
+			Expect(surplus).To(Equal(0))
+		})
+	})
+
+	var _ = Describe("proportional", func() {
+		var strategy app.DistributionStrategy
+
+		BeforeEach(func() {
+			var err error
+			strategy, err = app.NewDistributionStrategy("proportional")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("distributes the amount proportional to the targets", func() {
+			allocations, surplus := strategy.Distribute(5, []app.DistributionCandidate{
+				{Target: 2}, {Target: 4}, {Target: 4},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 2, 2}))
+			Expect(surplus).To(Equal(0))
+		})
+
+		It("distributes the remainder to the largest fractions first", func() {
+			allocations, surplus := strategy.Distribute(3, []app.DistributionCandidate{
+				{Target: 1}, {Target: 1}, {Target: 2},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 1, 1}))
+			Expect(surplus).To(Equal(0))
+		})
+
+		It("returns the amount exceeding all targets as surplus", func() {
+			allocations, surplus := strategy.Distribute(10, []app.DistributionCandidate{
+				{Target: 2}, {Target: 4},
+			})
+
+			Expect(allocations).To(Equal([]int{2, 4}))
+			Expect(surplus).To(Equal(4))
+		})
+	})
+
+	var _ = Describe("deepest_layer", func() {
+		var strategy app.DistributionStrategy
+
+		BeforeEach(func() {
+			var err error
+			strategy, err = app.NewDistributionStrategy("deepest_layer")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fills the candidates on the deepest layer first", func() {
+			allocations, surplus := strategy.Distribute(3, []app.DistributionCandidate{
+				{Target: 2, Layer: 2}, {Target: 2, Layer: 4}, {Target: 2, Layer: 3},
+			})
+
+			Expect(allocations).To(Equal([]int{0, 2, 1}))
+			Expect(surplus).To(Equal(0))
+		})
+
+		It("returns the amount exceeding all targets as surplus", func() {
+			allocations, surplus := strategy.Distribute(5, []app.DistributionCandidate{
+				{Target: 1, Layer: 2}, {Target: 2, Layer: 3},
+			})
+
+			Expect(allocations).To(Equal([]int{1, 2}))
+			Expect(surplus).To(Equal(2))
+		})
+	})
+})
@@ -37,7 +37,7 @@ func (e *encodingProviderImpl) GetFileEncoding(filePath string) (encoding.Encodi
 		return nil, fmt.Errorf("failed to detect encoding of file '%s': %w", filePath, err)
 	}
 
-	enc, err := e.getEncodingByName(result.Charset)
+	enc, err := getEncodingByName(result.Charset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get encoding: %w", err)
 	}
@@ -47,7 +47,7 @@ func (e *encodingProviderImpl) GetFileEncoding(filePath string) (encoding.Encodi
 	return enc, nil
 }
 
-func (e *encodingProviderImpl) getEncodingByName(name string) (encoding.Encoding, error) {
+func getEncodingByName(name string) (encoding.Encoding, error) {
 	switch strings.ToLower(name) {
 	case "utf-8":
 		return unicode.UTF8, nil
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+type ExportInventoryStep interface {
+	Export() error
+}
+
+type inventoryExporter struct {
+	config config.Config
+	logger utils.Logger
+}
+
+func NewExportInventoryStep(config config.Config, logger utils.Logger) ExportInventoryStep {
+	return &inventoryExporter{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (e *inventoryExporter) Export() error {
+	filePath := filepath.Join(e.config.GetResultDir(), "latest.csv")
+
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("no result found at '%s', the inventory has to be processed first", filePath)
+	}
+
+	encoding, err := NewEncodingProvider(e.logger).GetFileEncoding(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get encoding of file '%s': %w", filePath, err)
+	}
+
+	csvFile := NewCSVFile(e.logger)
+
+	content, err := csvFile.Read(filePath, encoding)
+	if err != nil {
+		return fmt.Errorf("failed to read CSV file '%s': %v", filePath, err)
+	}
+
+	inventoryData, err := NewInventoryData(content, e.config, e.logger)
+	if err != nil {
+		return fmt.Errorf("failed to init inventory data: %v", err)
+	}
+
+	thwinExport, err := NewTHWinExport(e.config, e.logger)
+	if err != nil {
+		return err
+	}
+
+	exportContent, err := thwinExport.Export(inventoryData)
+	if err != nil {
+		return fmt.Errorf("failed to export inventory: %v", err)
+	}
+
+	exportPath := filepath.Join(e.config.GetResultDir(), fmt.Sprintf("thwin_%s.csv", time.Now().Format("2006-01-02_15-04-05")))
+
+	csvFile, err = NewOutputCSVFile(e.config, e.logger)
+	if err != nil {
+		return err
+	}
+
+	err = csvFile.Write(exportPath, exportContent)
+	if err != nil {
+		return fmt.Errorf("failed to write export csv: %v", err)
+	}
+
+	e.logger.Info(fmt.Sprintf("exported %d rows to '%s'", len(exportContent)-1, exportPath))
+
+	return nil
+}
@@ -26,44 +26,49 @@ func (s *initInventoryCSVStep) Init() error {
 
 	filePath := s.config.GetAbsoluteInventoryCSVFileName()
 
-	encoding, err := NewEncodingProvider(s.logger).GetFileEncoding(filePath)
+	content, err := ReadInventoryFile(filePath, s.logger)
 	if err != nil {
-		return fmt.Errorf("failed to get encoding of file '%s': %w", filePath, err)
+		return err
 	}
 
-  csvFile := NewCSVFile(s.logger)
-
-  content, err := csvFile.Read(filePath, encoding)
-	if err != nil {
-		return fmt.Errorf("failed to read CSV file '%s': %v", s.config.GetAbsoluteInventoryCSVFileName(), err)
-	}
-
-  s.addActualEquipmentColumn(content)
-
 	inventoryData, err := NewInventoryData(content, s.config, s.logger)
 	if err != nil {
 		return fmt.Errorf("failed to init inventory data: %v", err)
 	}
 
-	inventoryData.GeneratePsydoEquipmentIDs()
+	inventoryData.AddActualColumn()
 
-	inventoryData.GetContent()
+	mappingPath := s.config.GetAbsolutePseudoIDMappingFileName()
 
-  csvFile.Write(s.config.GetAbsoluteInventoryCSVFileName(), inventoryData.GetContent())
+	var mapping PseudoIDMapping
+	if mappingPath != "" {
+		mapping, err = ReadPseudoIDMapping(mappingPath)
+		if err != nil {
+			return err
+		}
+	}
 
-  return nil
-}
+	err = inventoryData.GeneratePsydoEquipmentIDs(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to generate pseudo IDs: %w", err)
+	}
 
-func (s *initInventoryCSVStep) addActualEquipmentColumn(content CSVContent) {
-	for i := range content {
-		if i == 0 {
-			if content[i][len(content[i])-1] == s.config.Columns.EquipmentCountActual {
-				s.logger.Info(fmt.Sprintf("Skipping creation of column '%s'. It is existing already.", s.config.Columns.EquipmentCountActual))
-				break
-			}
-			content[i] = append(content[i], s.config.Columns.EquipmentCountActual)
-		} else {
-			content[i] = append(content[i], "")
+	if mapping != nil {
+		err = mapping.Write(mappingPath)
+		if err != nil {
+			return err
 		}
 	}
+
+	err = WriteInventoryFile(filePath, inventoryData, s.config, s.logger)
+	if err != nil {
+		return err
+	}
+
+	// saved after the file, a later change of the file is detected by its modification time
+	if databasePath := s.config.GetAbsoluteDatabaseFileName(); databasePath != "" {
+		return saveInventoryToDatabase(databasePath, "init", inventoryData.GetContent(), s.config, s.logger)
+	}
+
+	return nil
 }
@@ -0,0 +1,71 @@
+package app_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InitInventoryCSVStep", func() {
+
+	var (
+		tempDir string
+		cfg     *config.Config
+		logger  *utilsfakes.FakeLogger
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "init-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath := filepath.Join(tempDir, "config.json")
+		Expect(os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+			"working_dir": %q,
+			"inventory_csv_file_name": "inventory.csv",
+			"columns": {
+				"equipment_layer": "Ebene",
+				"equipment_part_number": "Sachnummer",
+				"equipment_id": "Inventar Nr",
+				"equipment_count_actual": "Bestand IST",
+				"equipment_count_target": "Menge",
+				"equipment_count_difference": "Differenz",
+				"equipment_status": "Inventurstatus"
+			}
+		}`, tempDir)), 0644)).To(Succeed())
+
+		logger = &utilsfakes.FakeLogger{}
+		cfg, err = config.LoadConfig(configPath, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(app.NewCSVFile(logger).Write(cfg.GetAbsoluteInventoryCSVFileName(), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge"},
+			{"1", "1111", "0591-S00001", "2"},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should not add the difference and status columns of the result", func() {
+		Expect(app.NewInitInventoryCSVStep(*cfg, logger).Init()).To(Succeed())
+
+		filePath := cfg.GetAbsoluteInventoryCSVFileName()
+		encoding, err := app.NewEncodingProvider(logger).GetFileEncoding(filePath)
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := app.NewCSVFile(logger).Read(filePath, encoding)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "0591-S00001", "2", ""},
+		}))
+	})
+})
@@ -12,24 +12,114 @@ type csvHeader map[string]int
 type csvHeaderReverse map[int]string
 type csvContent []map[string]string
 
+const (
+	StatusOK         = "OK"
+	StatusMissing    = "FEHLT"
+	StatusSurplus    = "ÜBERZÄHLIG"
+	StatusNotCounted = "NICHT ERFASST"
+)
+
+// EquipmentInfo describes an inventory row
+type EquipmentInfo struct {
+	Line        int
+	Layer       string
+	ID          string
+	PartNumber  string
+	Description string
+	ParentID    string
+	ParentLine  int
+	ParentPath  []string
+	Target      string
+	Actual      string
+	Unit        string
+}
+
+// SurplusMap holds the recorded amount per equipment ID which exceeds the inventory target
+type SurplusMap map[string]int
+
+// DuplicateScanMap holds the scans per equipment ID of unique equipment which exceed the target of 1
+// because they were recorded in further files, those are usually scanned twice by different helpers.
+// Repeated scans within one file are not counted as duplicates.
+type DuplicateScanMap map[string]int
+
+// UnknownScanMap holds the recorded amount per equipment ID which is not in the inventory
+type UnknownScanMap map[string]int
+
 type InventoryData interface {
 	GetContent() [][]string
 
-	UpdateInventory(recordedInventory RecordedInventoryMap) error
+	// GetResultContent returns the content with the computed columns, e.g. the count difference and status,
+	// those are only part of a result
+	GetResultContent() [][]string
+
+	// Columns returns the configured columns with the names found in the header
+	Columns() config.ConfigColumns
+
+	// AddActualColumn appends an empty actual count column if the inventory has none yet
+	AddActualColumn()
+
+	UpdateInventory(recordedInventory RecordedInventoryMap, origins ScanOriginsMap) error
+
+	GetSurplus() SurplusMap
+
+	GetUnknownScans() UnknownScanMap
+
+	GetDuplicateScans() DuplicateScanMap
+
+	// AnnotateInventory sets the condition and note columns of the annotated equipment
+	AnnotateInventory(annotations ScanAnnotationMap, origins ScanOriginsMap)
+
+	GetAuditLog() AuditLog
+
+	FindEquipment(id string) []EquipmentInfo
 
-	GeneratePsydoEquipmentIDs() error
+	GetEquipment() []EquipmentInfo
+
+	// GeneratePsydoEquipmentIDs reuses and extends the pinned IDs of the mapping, which may be nil
+	GeneratePsydoEquipmentIDs(mapping PseudoIDMapping) error
 }
 
 type inventoryData struct {
 	csvHeader        csvHeader
 	csvHeaderReverse csvHeaderReverse
 	content          csvContent
+	distribution     DistributionStrategy
+	pseudoIDs        PseudoIDScheme
+	normalizer       ScanNormalizer
+	surplus          SurplusMap
+	unknownScans     UnknownScanMap
+	duplicateScans   DuplicateScanMap
+	annotations      ScanAnnotationMap
+	auditLog         AuditLog
 	config           config.Config
 	logger           utils.Logger
 }
 
 func NewInventoryData(data [][]string, config config.Config, logger utils.Logger) (InventoryData, error) {
 
+	distribution, err := NewDistributionStrategy(config.CountDistribution)
+	if err != nil {
+		return nil, err
+	}
+
+	pseudoIDs, err := NewPseudoIDScheme(config)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer, err := NewScanNormalizer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// the configured columns are used with the names found in the header from here on
+	if len(data) > 0 {
+		config.Columns, err = config.Columns.Resolve(data[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	csvHeader := make(csvHeader)
 
 	var content csvContent
@@ -61,19 +151,47 @@ func NewInventoryData(data [][]string, config config.Config, logger utils.Logger
 		csvHeader:        csvHeader,
 		csvHeaderReverse: csvHeaderReverse,
 		content:          content,
+		distribution:     distribution,
+		pseudoIDs:        pseudoIDs,
+		normalizer:       normalizer,
+		surplus:          make(SurplusMap),
 		config:           config,
 		logger:           logger,
 	}, nil
 }
 
 func (c *inventoryData) GetContent() [][]string {
+	return c.contentWith(nil)
+}
+
+func (c *inventoryData) GetResultContent() [][]string {
+	return c.contentWith(c.computedColumns())
+}
+
+func (c *inventoryData) contentWith(computedColumns []computedColumn) [][]string {
 	var result [][]string
 
-	for _, row := range c.content {
+	header := c.header()
+
+	for i, row := range c.content {
 		var resultRow []string
 
-		for i := 0; i < len(c.csvHeaderReverse); i++ {
-			resultRow = append(resultRow, row[c.csvHeaderReverse[i]])
+		for j := 0; j < len(c.csvHeaderReverse); j++ {
+			resultRow = append(resultRow, row[c.csvHeaderReverse[j]])
+		}
+
+		for _, column := range computedColumns {
+			value := column.name
+			if i > 0 {
+				value = column.value(row)
+			}
+
+			// overwrite the column if it exists already, e.g. in a previous result
+			if index := indexOf(header, column.name); index >= 0 {
+				resultRow[index] = value
+			} else {
+				resultRow = append(resultRow, value)
+			}
 		}
 
 		result = append(result, resultRow)
@@ -82,121 +200,725 @@ func (c *inventoryData) GetContent() [][]string {
 	return result
 }
 
-func (c *inventoryData) UpdateInventory(recordedInventory RecordedInventoryMap) error {
+func (c *inventoryData) Columns() config.ConfigColumns {
+	return c.config.Columns
+}
+
+func (c *inventoryData) AddActualColumn() {
+	if len(c.content) == 0 {
+		return
+	}
+
+	name := c.config.Columns.EquipmentCountActual
+	if _, ok := c.csvHeader[name]; ok {
+		c.logger.Info(fmt.Sprintf("Skipping creation of column '%s'. It is existing already.", name))
+		return
+	}
+
+	// the other rows return an empty value for the missing key
+	index := len(c.csvHeaderReverse)
+	c.csvHeader[name] = index
+	c.csvHeaderReverse[index] = name
+	c.content[0][name] = name
+}
+
+func (c *inventoryData) header() []string {
+	var header []string
+	for j := 0; j < len(c.csvHeaderReverse); j++ {
+		header = append(header, c.csvHeaderReverse[j])
+	}
+	return header
+}
 
-	firstEquipment := true
+type computedColumn struct {
+	name  string
+	value func(row map[string]string) string
+}
 
-	for inventory, amount := range recordedInventory {
-		inventoryFound := false
-		actualValue := strconv.Itoa(amount)
+func (c *inventoryData) computedColumns() []computedColumn {
+	var columns []computedColumn
 
-		for _, row := range c.content {
-			configColumns := c.config.Columns
+	if c.config.Columns.EquipmentCountDifference != "" {
+		columns = append(columns, computedColumn{
+			name:  c.config.Columns.EquipmentCountDifference,
+			value: c.countDifference,
+		})
+	}
+	if c.config.Columns.EquipmentStatus != "" {
+		columns = append(columns, computedColumn{
+			name:  c.config.Columns.EquipmentStatus,
+			value: c.countStatus,
+		})
+	}
+
+	// the annotation columns are only added or recomputed if a command barcode was used, rows without
+	// annotation keep the values of existing columns, e.g. entered manually
+	if len(c.annotations) == 0 {
+		return columns
+	}
+	conditionColumn, noteColumn := c.annotationColumns()
+	existingCondition, existingNote := c.existingColumn(conditionColumn), c.existingColumn(noteColumn)
+	columns = append(columns, computedColumn{
+		name: conditionColumn,
+		value: func(row map[string]string) string {
+			return valueOr(c.annotations.Conditions(c.annotationKey(row)), row[existingCondition])
+		},
+	})
+	columns = append(columns, computedColumn{
+		name: noteColumn,
+		value: func(row map[string]string) string {
+			return valueOr(c.annotations.Notes(c.annotationKey(row)), row[existingNote])
+		},
+	})
+
+	return columns
+}
+
+// countDifference returns IST - SOLL, or an empty string if the row has no target or was not counted
+func (c *inventoryData) countDifference(row map[string]string) string {
+	target, _, err := c.target(row)
+	if err != nil {
+		return ""
+	}
+	actual, _, err := ParseQuantity(row[c.config.Columns.EquipmentCountActual])
+	if err != nil {
+		return ""
+	}
+	return (actual - target).String()
+}
+
+func (c *inventoryData) countStatus(row map[string]string) string {
+	target, _, err := c.target(row)
+	if err != nil {
+		return ""
+	}
+	actual, _, err := ParseQuantity(row[c.config.Columns.EquipmentCountActual])
+	if err != nil {
+		return StatusNotCounted
+	}
+
+	switch {
+	case actual < target:
+		return StatusMissing
+	case actual > target:
+		return StatusSurplus
+	default:
+		return StatusOK
+	}
+}
+
+// target returns the target of a row with its unit, the unit column takes precedence over a unit in the target column
+func (c *inventoryData) target(row map[string]string) (Quantity, string, error) {
+	target, _, err := ParseQuantity(row[c.config.Columns.EquipmentCountTarget])
+	if err != nil {
+		return 0, "", err
+	}
+
+	return target, c.unit(row), nil
+}
+
+func (c *inventoryData) UpdateInventory(recordedInventory RecordedInventoryMap, origins ScanOriginsMap) error {
+
+	configColumns := c.config.Columns
+	c.surplus = make(SurplusMap)
+	c.unknownScans = make(UnknownScanMap)
+	c.duplicateScans = make(DuplicateScanMap)
+	c.auditLog = nil
+
+	// the IDs are compared like the normalized scans
+	ids := make([]string, len(c.content))
+	for i := 1; i < len(c.content); i++ {
+		ids[i] = c.normalizer.Normalize(c.content[i][configColumns.EquipmentID])
+	}
+
+	// the audit log follows the order of the IDs
+	for _, inventory := range SortScanIDs(recordedInventory, origins, config.ReportOrderID) {
+		amount := recordedInventory[inventory]
+		var rows []map[string]string
+		var lines []int
+		var candidates []DistributionCandidate
+		var measuredTargets []*Quantity
+		found := false
+
+		// skip the header row
+		for i := 1; i < len(c.content); i++ {
+			row := c.content[i]
 
 			// ignore case comparison
-			if strings.EqualFold(row[configColumns.EquipmentID], inventory) {
-				inventoryFound = true
-
-				if configColumns.EquipmentCountTarget != "" {
-					targetValueInt, err := strconv.Atoi(row[configColumns.EquipmentCountTarget])
-					if err != nil {
-						return fmt.Errorf("error converting target value to int: %v", err)
-					}
-					if amount >= targetValueInt {
-						amount = amount - targetValueInt
-						actualValue = strconv.Itoa(targetValueInt)
-					}
+			if !strings.EqualFold(ids[i], inventory) {
+				continue
+			}
+			found = true
+
+			candidate := DistributionCandidate{Unlimited: true}
+			var measuredTarget *Quantity
+
+			if configColumns.EquipmentCountTarget != "" {
+				target, unit, err := c.target(row)
+
+				switch {
+				case err != nil:
+					err = fmt.Errorf("failed to convert column '%s' to number on line %d", configColumns.EquipmentCountTarget, i+1)
+				case IsPieceUnit(unit) && !target.IsWhole():
+					err = fmt.Errorf("column '%s' on line %d is no whole number of pieces", configColumns.EquipmentCountTarget, i+1)
 				}
 
-				row[configColumns.EquipmentCountActual] = actualValue
-				actualValue = strconv.Itoa(amount);
+				switch {
+				case err != nil && c.config.TargetPolicy == config.TargetPolicyError:
+					return err
+				case err != nil && c.config.TargetPolicy == config.TargetPolicySkip:
+					c.logger.Warn(fmt.Sprintf("%v, the line is skipped", err))
+					continue
+				case err != nil:
+					c.logger.Warn(fmt.Sprintf("%v, the target is ignored", err))
+				case IsPieceUnit(unit):
+					candidate = DistributionCandidate{Target: int(target)}
+				default:
+					// a measured amount like meters of a rope is counted completely by a single scan
+					candidate = DistributionCandidate{Target: 1}
+					measuredTarget = &target
+				}
 			}
+
+			if layer, err := strconv.Atoi(row[configColumns.EquipmentLayer]); err == nil {
+				candidate.Layer = layer
+			}
+
+			rows = append(rows, row)
+			lines = append(lines, i+1)
+			candidates = append(candidates, candidate)
+			measuredTargets = append(measuredTargets, measuredTarget)
+		}
+
+		if !found {
+			c.unknownScans[inventory] += amount
+			continue
 		}
 
-		if !inventoryFound {
-			if firstEquipment {
-				c.logger.Info("recorded equipment not available in the inventory:")
-				c.logger.Info("")
-				c.logger.WarnIndented("equipment     : amount")
-				c.logger.WarnIndented("----------------------")
-				firstEquipment = false
+		allocations, surplus := c.distribution.Distribute(amount, candidates)
+		for i, row := range rows {
+			newValue := strconv.Itoa(allocations[i])
+			if measuredTargets[i] != nil && allocations[i] > 0 {
+				newValue = measuredTargets[i].String()
+			}
+
+			rule := c.distribution.Name()
+			if candidates[i].Unlimited {
+				rule = AuditRuleNoTarget
 			}
 
-			c.logger.WarnIndented(fmt.Sprintf("%-13s : %5d", inventory, amount))
+			c.auditLog = append(c.auditLog, AuditEvent{
+				ScanID:   inventory,
+				Sources:  origins[inventory],
+				Line:     lines[i],
+				OldValue: row[configColumns.EquipmentCountActual],
+				NewValue: newValue,
+				Rule:     rule,
+			})
+
+			row[configColumns.EquipmentCountActual] = newValue
+		}
+
+		// only the scans in further files are duplicates, repeated scans within a file stay surplus
+		if duplicates := min(surplus, otherFiles(origins[inventory])); duplicates > 0 && isUniqueEquipment(candidates) {
+			c.duplicateScans[inventory] += duplicates
+
+			if c.config.DuplicateScanPolicy == config.DuplicateScanPolicyCap {
+				c.auditLog = append(c.auditLog, AuditEvent{
+					ScanID:   inventory,
+					Sources:  origins[inventory],
+					NewValue: strconv.Itoa(duplicates),
+					Rule:     AuditRuleDuplicate,
+				})
+				surplus -= duplicates
+			}
 		}
-	}
 
-	if !firstEquipment {
-		c.logger.Info("")
+		if surplus > 0 {
+			c.surplus[inventory] += surplus
+
+			c.auditLog = append(c.auditLog, AuditEvent{
+				ScanID:   inventory,
+				Sources:  origins[inventory],
+				NewValue: strconv.Itoa(surplus),
+				Rule:     AuditRuleSurplus,
+			})
+		}
 	}
 
+	reporter := NewReporter(c.logger)
+	reporter.Table(c.amountTable("recorded equipment not available in the inventory:", "amount", c.unknownScans, origins))
+	reporter.Table(c.duplicateTable(origins))
+	reporter.Table(c.amountTable("recorded equipment exceeding the inventory target:", "surplus", c.surplus, origins))
+
 	return nil
 }
 
-func (c *inventoryData) GeneratePsydoEquipmentIDs() error {
+func (c *inventoryData) duplicateTable(origins ScanOriginsMap) ReportTable {
+	title := "unique equipment scanned in several files, counted as surplus:"
+	if c.config.DuplicateScanPolicy == config.DuplicateScanPolicyCap {
+		title = "unique equipment scanned in several files, counted once:"
+	}
+
+	table := ReportTable{
+		Title:   title,
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: "duplicates", AlignRight: true}, {Header: "scans"}},
+		Warning: true,
+	}
+	for _, id := range SortScanIDs(c.duplicateScans, origins, c.config.ReportOrder) {
+		var sources []string
+		for _, origin := range origins[id] {
+			sources = append(sources, fmt.Sprintf("%s:%d", origin.File, origin.Line))
+		}
+		table.Rows = append(table.Rows, []string{id, strconv.Itoa(c.duplicateScans[id]), strings.Join(sources, ", ")})
+	}
+	return table
+}
+
+// isUniqueEquipment returns true if the rows of an ID have a target of 1 piece in total
+func isUniqueEquipment(candidates []DistributionCandidate) bool {
+	total := 0
+	for _, candidate := range candidates {
+		if candidate.Unlimited {
+			return false
+		}
+		total += candidate.Target
+	}
+	return total == 1
+}
+
+// otherFiles returns the number of files with scans besides the first one
+func otherFiles(origins []ScanOrigin) int {
+	files := make(map[string]bool)
+	for _, origin := range origins {
+		files[origin.File] = true
+	}
+	return max(len(files)-1, 0)
+}
+
+func (c *inventoryData) amountTable(title string, amountHeader string, amounts map[string]int, origins ScanOriginsMap) ReportTable {
+	table := ReportTable{
+		Title:   title,
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: amountHeader, AlignRight: true}},
+		Warning: true,
+	}
+	for _, id := range SortScanIDs(amounts, origins, c.config.ReportOrder) {
+		table.Rows = append(table.Rows, []string{id, strconv.Itoa(amounts[id])})
+	}
+	return table
+}
+
+func (c *inventoryData) GetSurplus() SurplusMap {
+	return c.surplus
+}
+
+func (c *inventoryData) AnnotateInventory(annotations ScanAnnotationMap, origins ScanOriginsMap) {
+	c.annotations = make(ScanAnnotationMap)
+
+	// ignore case comparison with the normalized IDs of the inventory, see annotationKey
+	for scan, scanAnnotations := range annotations {
+		key := strings.ToLower(scan)
+		c.annotations[key] = append(c.annotations[key], scanAnnotations...)
+	}
+
+	table := ReportTable{
+		Title:   "annotated equipment:",
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: "condition"}, {Header: "note"}, {Header: "scan"}},
+	}
+	for _, id := range SortScanIDs(annotationCounts(annotations), origins, c.config.ReportOrder) {
+		for _, annotation := range annotations[id] {
+			table.Rows = append(table.Rows, []string{
+				id, annotation.Condition, annotation.Note, fmt.Sprintf("%s:%d", annotation.Origin.File, annotation.Origin.Line),
+			})
+		}
+	}
+	NewReporter(c.logger).Table(table)
+}
+
+// existingColumn returns the name of the matching header, or an empty string if the column does not exist
+func (c *inventoryData) existingColumn(name string) string {
+	if index := indexOf(c.header(), name); index >= 0 {
+		return c.csvHeaderReverse[index]
+	}
+	return ""
+}
+
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func annotationCounts(annotations ScanAnnotationMap) map[string]int {
+	counts := make(map[string]int)
+	for id, idAnnotations := range annotations {
+		counts[id] = len(idAnnotations)
+	}
+	return counts
+}
+
+func (c *inventoryData) annotationKey(row map[string]string) string {
+	return c.normalizer.Key(row[c.config.Columns.EquipmentID])
+}
+
+func (c *inventoryData) annotationColumns() (string, string) {
+	condition, note := c.config.Columns.EquipmentCondition, c.config.Columns.EquipmentNote
+	if condition == "" {
+		condition = DefaultConditionColumn
+	}
+	if note == "" {
+		note = DefaultNoteColumn
+	}
+	return condition, note
+}
+
+func (c *inventoryData) GetDuplicateScans() DuplicateScanMap {
+	return c.duplicateScans
+}
+
+func (c *inventoryData) GetUnknownScans() UnknownScanMap {
+	return c.unknownScans
+}
+
+func (c *inventoryData) GetAuditLog() AuditLog {
+	return c.auditLog
+}
+
+func (c *inventoryData) FindEquipment(id string) []EquipmentInfo {
+	var result []EquipmentInfo
+
+	id = c.normalizer.Normalize(id)
+
+	// skip the header row
+	for i := 1; i < len(c.content); i++ {
+		// ignore case comparison
+		if strings.EqualFold(c.normalizer.Normalize(c.content[i][c.config.Columns.EquipmentID]), id) {
+			result = append(result, c.equipmentInfo(i))
+		}
+	}
+
+	return result
+}
+
+func (c *inventoryData) GetEquipment() []EquipmentInfo {
+	var result []EquipmentInfo
+
+	// skip the header row
+	for i := 1; i < len(c.content); i++ {
+		result = append(result, c.equipmentInfo(i))
+	}
+
+	return result
+}
+
+func (c *inventoryData) equipmentInfo(index int) EquipmentInfo {
+	columns := c.config.Columns
+	row := c.content[index]
+	ancestors := c.ancestors(index)
+
+	parentLine := 0
+	if len(ancestors) > 0 {
+		parentLine = ancestors[len(ancestors)-1] + 1
+	}
+
+	return EquipmentInfo{
+		Line:        index + 1,
+		Layer:       row[columns.EquipmentLayer],
+		ID:          row[columns.EquipmentID],
+		PartNumber:  row[columns.EquipmentPartNumber],
+		Description: c.description(row),
+		ParentID:    c.parentID(ancestors),
+		ParentLine:  parentLine,
+		ParentPath:  c.parentPath(ancestors),
+		Target:      row[columns.EquipmentCountTarget],
+		Actual:      row[columns.EquipmentCountActual],
+		Unit:        c.unit(row),
+	}
+}
+
+func (c *inventoryData) description(row map[string]string) string {
+	if c.config.Columns.EquipmentDescription == "" {
+		return row[c.config.Columns.EquipmentPartNumber]
+	}
+	return strings.TrimSpace(row[c.config.Columns.EquipmentDescription])
+}
+
+func (c *inventoryData) unit(row map[string]string) string {
+	if value := strings.TrimSpace(row[c.config.Columns.EquipmentUnit]); c.config.Columns.EquipmentUnit != "" && value != "" {
+		return value
+	}
+	_, unit, _ := ParseQuantity(row[c.config.Columns.EquipmentCountTarget])
+	return unit
+}
+
+// ancestors returns the indexes of the rows on the upper layers, starting with layer 1
+func (c *inventoryData) ancestors(index int) []int {
+	layerColumn := c.config.Columns.EquipmentLayer
+
+	layer, err := strconv.Atoi(c.content[index][layerColumn])
+	if err != nil {
+		return nil
+	}
+
+	var result []int
+	for j := index - 1; j > 0 && layer > 1; j-- {
+		previousLayer, err := strconv.Atoi(c.content[j][layerColumn])
+		if err != nil {
+			continue
+		}
+
+		if previousLayer == layer-1 {
+			result = append([]int{j}, result...)
+			layer = previousLayer
+		}
+	}
+
+	return result
+}
+
+// parentPath returns the descriptions of the rows on the upper layers, starting with layer 1
+func (c *inventoryData) parentPath(ancestors []int) []string {
+	var path []string
+	for _, j := range ancestors {
+		path = append(path, c.description(c.content[j]))
+	}
+	return path
+}
+
+// parentID returns the nearest real equipment ID on the upper layers
+func (c *inventoryData) parentID(ancestors []int) string {
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		id := c.content[ancestors[i]][c.config.Columns.EquipmentID]
+		if c.pseudoIDs.IsRealID(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// pseudoIDSource is a line which gets a pseudo ID of its parent ID and part number
+type pseudoIDSource struct {
+	index      int
+	parentID   string
+	partNumber string
+}
+
+func (c *inventoryData) GeneratePsydoEquipmentIDs(mapping PseudoIDMapping) error {
 
 	content := c.content
 	columns := c.config.Columns
+	var sources []pseudoIDSource
+
 	// Forward iteration
 	for i := range content {
-		if !utils.StartsWithNumber(content[i][columns.EquipmentID]) {
-			equipmentLayer, err := strconv.Atoi(content[i][columns.EquipmentLayer])
-			if err != nil && i > 0 {
-				c.logger.Warn(fmt.Sprintf("failed to convert column '%s' to number on line %d", columns.EquipmentLayer, i+1))
-				continue
+		id := content[i][columns.EquipmentID]
+		if i == 0 || c.pseudoIDs.IsRealID(id) || c.pseudoIDs.IsPseudoID(id) {
+			continue
+		}
+
+		equipmentLayer, err := strconv.Atoi(content[i][columns.EquipmentLayer])
+		if err != nil {
+			c.logger.Warn(fmt.Sprintf("failed to convert column '%s' to number on line %d", columns.EquipmentLayer, i+1))
+			continue
+		}
+
+		searchedEquipmentLayer := equipmentLayer - 1
+		searchPath := fmt.Sprintf("%d", i+1)
+
+		// iterate backwards to find the last equipment number in upper layers
+		for j := i - 1; j >= 0; j-- {
+			if searchedEquipmentLayer <= 0 {
+				msg := fmt.Sprintf(
+					"skipping ID generation for line %d (processed lines %s). Could not find a '%s' value up to '%s' 1",
+					i+1,
+					searchPath,
+					columns.EquipmentID,
+					columns.EquipmentLayer,
+				)
+				c.logger.Warn(msg)
+
+				break
 			}
 
-			searchedEquipmentLayer := equipmentLayer - 1
-			searchPath := fmt.Sprintf("%d", i+1)
+			previousLineEquipmentLayer, err := strconv.Atoi(content[j][columns.EquipmentLayer])
+			if err != nil {
+				searchPath = searchPath + fmt.Sprintf(", %d", j+1)
 
-			// iterate backwards to find the last equipment number in upper layers
-			for j := i - 1; j >= 0; j-- {
-				if searchedEquipmentLayer <= 0 {
-					msg := fmt.Sprintf(
-						"skipping ID generation for line %d (processed lines %s). Could not find a '%s' value up to '%s' 1",
-						i+1,
-						searchPath,
-						columns.EquipmentID,
-						columns.EquipmentLayer,
-					)
-					c.logger.Warn(msg)
+				msg := fmt.Sprintf(
+					"skipping ID generation for line %d (processed lines %s). Column '%s' of line %d cannot be converted to number",
+					i+1,
+					searchPath,
+					columns.EquipmentLayer,
+					j+1,
+				)
+				c.logger.Warn(msg)
 
-					break
-				}
+				break
+			}
 
-				previousLineEquipmentLayer, err := strconv.Atoi(content[j][columns.EquipmentLayer])
-				if err != nil {
-					searchPath = searchPath + fmt.Sprintf(", %d", j+1)
+			if previousLineEquipmentLayer == searchedEquipmentLayer {
+				searchPath = searchPath + fmt.Sprintf(", %d", j+1)
 
-					msg := fmt.Sprintf(
-						"skipping ID generation for line %d (processed lines %s). Column '%s' of line %d cannot be converted to number",
-						i+1,
-						searchPath,
-						columns.EquipmentLayer,
-						j+1,
-					)
-					c.logger.Warn(msg)
+				if c.pseudoIDs.IsRealID(content[j][columns.EquipmentID]) {
+					sources = append(sources, pseudoIDSource{
+						index:      i,
+						parentID:   content[j][columns.EquipmentID],
+						partNumber: content[i][columns.EquipmentPartNumber],
+					})
+
+					msg := fmt.Sprintf("created ID for line %d (processed lines %s)", i+1, searchPath)
+					c.logger.Info(msg)
 
 					break
+				} else {
+					searchedEquipmentLayer = searchedEquipmentLayer - 1
 				}
+			}
+		}
+	}
+
+	return c.assignPseudoIDs(sources, mapping)
+}
 
-				if previousLineEquipmentLayer == searchedEquipmentLayer {
-					searchPath = searchPath + fmt.Sprintf(", %d", j+1)
+// assignPseudoIDs sets the pinned or generated IDs and resolves IDs which would be used by several lines
+func (c *inventoryData) assignPseudoIDs(sources []pseudoIDSource, mapping PseudoIDMapping) error {
+	if mapping == nil {
+		mapping = NewPseudoIDMapping()
+	}
 
-					if utils.StartsWithNumber(content[j][columns.EquipmentID]) && !strings.Contains(content[j][columns.EquipmentID], "__") {
-						content[i][columns.EquipmentID] = content[j][columns.EquipmentID] + "__" + content[i][columns.EquipmentPartNumber]
+	identities := c.pseudoIDIdentities(sources)
+	ids := make([]string, len(sources))
+	lines := make(map[string][]int)
+	var unpinned []int
 
-						msg := fmt.Sprintf("created ID for line %d (processed lines %s)", i+1, searchPath)
-						c.logger.Info(msg)
+	for k, source := range sources {
+		if id, ok := mapping.Lookup(identities[k]); ok {
+			ids[k] = id
+			continue
+		}
 
-						break
-					} else {
-						searchedEquipmentLayer = searchedEquipmentLayer - 1
-					}
+		id, err := c.pseudoIDs.Generate(source.parentID, source.partNumber, 0)
+		if err != nil {
+			return err
+		}
+		ids[k] = id
+		lines[id] = append(lines[id], k)
+		unpinned = append(unpinned, k)
+	}
+
+	var collisions []string
+	for _, k := range unpinned {
+		source := sources[k]
+		shared := lines[ids[k]]
+
+		if len(shared) > 1 {
+			if !c.pseudoIDs.OrdinalSuffix() {
+				if shared[0] == k {
+					collisions = append(collisions, ids[k])
+				}
+			} else {
+				ordinal := 1
+				for shared[ordinal-1] != k {
+					ordinal++
+				}
+
+				var err error
+				ids[k], err = c.pseudoIDs.Generate(source.parentID, source.partNumber, ordinal)
+				if err != nil {
+					return err
 				}
 			}
 		}
+
+		// an ID pinned before must not change its meaning
+		owner, pinned := mapping.Owner(ids[k])
+		if pinned && (c.pseudoIDs.OrdinalSuffix() || !owner.sameItem(identities[k])) {
+			id, err := c.freePseudoID(source, identities[k].Ordinal, mapping)
+			if err != nil {
+				return err
+			}
+
+			if !owner.sameItem(identities[k]) {
+				c.logger.Warn(fmt.Sprintf("pseudo ID '%s' is pinned to %s, line %d gets '%s'", ids[k], owner, source.index+1, id))
+			}
+			ids[k] = id
+		}
+
+		mapping.Pin(identities[k], ids[k])
+	}
+
+	for k, source := range sources {
+		c.content[source.index][c.config.Columns.EquipmentID] = ids[k]
+	}
+
+	reporter := NewReporter(c.logger)
+
+	collisionTable := ReportTable{
+		Title:   "generated IDs used by several lines, enable pseudo_id.ordinal_suffix to tell them apart:",
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: "lines"}},
+		Warning: true,
+	}
+	for _, id := range collisions {
+		var sharedLines []string
+		for _, k := range lines[id] {
+			sharedLines = append(sharedLines, strconv.Itoa(sources[k].index+1))
+		}
+		collisionTable.Rows = append(collisionTable.Rows, []string{id, strings.Join(sharedLines, ", ")})
+	}
+	reporter.Table(collisionTable)
+
+	// lines of a file initialized before keep their IDs and are no sources
+	var orphaned []PseudoIDMappingEntry
+	for _, entry := range mapping.Orphaned(identities) {
+		if len(c.FindEquipment(entry.ID)) == 0 {
+			orphaned = append(orphaned, entry)
+		}
 	}
 
+	orphanTable := ReportTable{
+		Title:   "pinned pseudo IDs without line in the inventory:",
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: "pinned to"}},
+		Warning: true,
+	}
+	for _, entry := range orphaned {
+		orphanTable.Rows = append(orphanTable.Rows, []string{entry.ID, entry.PseudoIDIdentity.String()})
+	}
+	reporter.Table(orphanTable)
+
 	return nil
 }
+
+// pseudoIDIdentities numbers the lines with the same parent ID, part number and description
+func (c *inventoryData) pseudoIDIdentities(sources []pseudoIDSource) []PseudoIDIdentity {
+	identities := make([]PseudoIDIdentity, len(sources))
+	ordinals := make(map[PseudoIDIdentity]int)
+
+	for k, source := range sources {
+		identity := PseudoIDIdentity{
+			ParentID:    source.parentID,
+			PartNumber:  source.partNumber,
+			Description: c.description(c.content[source.index]),
+		}
+		ordinals[identity]++
+
+		identity.Ordinal = ordinals[identity]
+		identities[k] = identity
+	}
+
+	return identities
+}
+
+// freePseudoID returns the first ID with an ordinal suffix which is not pinned yet
+func (c *inventoryData) freePseudoID(source pseudoIDSource, ordinal int, mapping PseudoIDMapping) (string, error) {
+	for {
+		id, err := c.pseudoIDs.Generate(source.parentID, source.partNumber, ordinal)
+		if err != nil {
+			return "", err
+		}
+		if _, pinned := mapping.Owner(id); !pinned {
+			return id, nil
+		}
+		ordinal++
+	}
+}
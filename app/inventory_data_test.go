@@ -1,6 +1,7 @@
 package app_test
 
 import (
+	"encoding/json"
 	"thwInventoryMerge/app"
 	"thwInventoryMerge/config"
 	"thwInventoryMerge/utils/utilsfakes"
@@ -50,6 +51,17 @@ var _ = Describe("CSVFile", func() {
 			Expect(content[3][3]).To(Equal("U"))
 		})
 
+		It("returns an error listing the available columns if a configured column is missing", func() {
+			csvData := [][]string{
+				{"Ebene", "Ausstattung", "Inventar-Nr."},
+				{"1", "Handlampe", "0591-S00001"}}
+
+			_, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{EquipmentLayer: "Ebene", EquipmentID: "Inventar Nr"},
+			}, nil)
+			Expect(err).To(MatchError("column equipment_id ('Inventar Nr') not found, available columns: 'Ebene', 'Ausstattung', 'Inventar-Nr.'"))
+		})
+
 		It("preserve leading and trailing spaces", func() {
 			csvData := [][]string{
 				{"Verfügbar", "Ausstattung", "Inventar Nr", "Status"},
@@ -69,6 +81,85 @@ var _ = Describe("CSVFile", func() {
 			Expect(content[1][2]).To(Equal(" 0591-S00001 "))
 			Expect(content[1][3]).To(Equal("V  "))
 		})
+
+		It("appends the configured difference and status columns", func() {
+			csvData := [][]string{
+				{"Bestand IST", "Menge", "Ausstattung", "Inventar Nr"},
+				{"4", "4", "Handlampe", "0591-S00001"},
+				{"1", "2", "Fuchsschwanz", "0591-S00002"},
+				{"3", "2", "Rettungsweste", "0591-S00003"},
+				{"", "1", "Spanngurt", "0591-S00004"},
+				{"", "", "Geringwertiges Material", ""}}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:              "Inventar Nr",
+					EquipmentCountActual:     "Bestand IST",
+					EquipmentCountTarget:     "Menge",
+					EquipmentCountDifference: "Differenz",
+					EquipmentStatus:          "Inventurstatus",
+				},
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			content := data.GetResultContent()
+
+			Expect(content[0]).To(Equal([]string{"Bestand IST", "Menge", "Ausstattung", "Inventar Nr", "Differenz", "Inventurstatus"}))
+			Expect(content[1][4:]).To(Equal([]string{"0", "OK"}))
+			Expect(content[2][4:]).To(Equal([]string{"-1", "FEHLT"}))
+			Expect(content[3][4:]).To(Equal([]string{"1", "ÜBERZÄHLIG"}))
+			Expect(content[4][4:]).To(Equal([]string{"", "NICHT ERFASST"}))
+			Expect(content[5][4:]).To(Equal([]string{"", ""}))
+		})
+
+		It("overwrites existing difference and status columns", func() {
+			csvData := [][]string{
+				{"Bestand IST", "Menge", "Differenz", "Inventurstatus"},
+				{"1", "2", "0", "OK"}}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentCountActual:     "Bestand IST",
+					EquipmentCountTarget:     "Menge",
+					EquipmentCountDifference: "Differenz",
+					EquipmentStatus:          "Inventurstatus",
+				},
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			content := data.GetResultContent()
+
+			Expect(content[0]).To(Equal([]string{"Bestand IST", "Menge", "Differenz", "Inventurstatus"}))
+			Expect(content[1]).To(Equal([]string{"1", "2", "-1", "FEHLT"}))
+		})
+
+		It("adds the actual column with the configured name and resolves the other columns", func() {
+			csvData := [][]string{
+				{"Ebene", "Sachnummer", "Inventar-Nr."},
+				{"1", "1111", "0591-S00001"}}
+
+			var columns config.ConfigColumns
+			Expect(json.Unmarshal([]byte(`{
+				"equipment_layer": "Ebene",
+				"equipment_part_number": 2,
+				"equipment_id": ["Inventar Nr", "Inventar-Nr."],
+				"equipment_count_actual": ["Bestand IST", "Ist"]
+			}`), &columns)).To(Succeed())
+
+			data, err := app.NewInventoryData(csvData, config.Config{Columns: columns}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			data.AddActualColumn()
+
+			Expect(data.Columns().EquipmentPartNumber).To(Equal("Sachnummer"))
+			Expect(data.Columns().EquipmentID).To(Equal("Inventar-Nr."))
+			Expect(data.GetContent()).To(Equal([][]string{
+				{"Ebene", "Sachnummer", "Inventar-Nr.", "Bestand IST"},
+				{"1", "1111", "0591-S00001", ""}}))
+
+			data.AddActualColumn()
+			Expect(data.GetContent()[0]).To(HaveLen(4))
+		})
 	})
 
 	var _ = Describe("UpdateInventory", func() {
@@ -91,7 +182,7 @@ var _ = Describe("CSVFile", func() {
 				"0591-S00001": 100,
 				"1234":        101,
 				"0591-S00002": 0,
-			})
+			}, nil)
 
 			content := data.GetContent()
 
@@ -130,7 +221,7 @@ var _ = Describe("CSVFile", func() {
 
 			data.UpdateInventory(app.RecordedInventoryMap{
 				"0591-S00001": 100,
-			})
+			}, nil)
 
 			content := data.GetContent()
 
@@ -169,7 +260,7 @@ var _ = Describe("CSVFile", func() {
 
 			data.UpdateInventory(app.RecordedInventoryMap{
 				"0591-S00001__1111": 10,
-			})
+			}, nil)
 
 			content := data.GetContent()
 
@@ -182,6 +273,416 @@ var _ = Describe("CSVFile", func() {
 			Expect(content[7][0]).To(Equal("0")) // we collected only 10 items
 		})
 
+		It("does not count a partially filled remainder twice", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "5", "Spanngurt", "0591-S00001__1111"},
+				{"", "5", "Spanngurt", "0591-S00001__1111"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001__1111": 3,
+			}, nil)
+
+			content := data.GetContent()
+
+			Expect(content[1][0]).To(Equal("3"))
+			Expect(content[2][0]).To(Equal("0"))
+		})
+
+		It("tracks the recorded values exceeding EquipmentCountTarget as surplus", func() {
+			logger := &utilsfakes.FakeLogger{}
+
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "1", "Handlampe", "0591-S00001"},
+				{"", "2", "Spanngurt", "0591-S00002"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-s00001": 3,
+				"0591-s00002": 2,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetSurplus()).To(Equal(app.SurplusMap{"0591-s00001": 2}))
+
+			Expect(logger.WarnIndentedCallCount()).To(Equal(3))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("0591-s00001 :       2"))
+		})
+
+		It("adds the condition and note of the annotated equipment as columns", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "1", "Handlampe", "0591-S00001"},
+				{"", "1", "Spanngurt", "0591-S00002"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+					EquipmentNote:        "Hinweis",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetResultContent()[0]).To(HaveLen(4))
+
+			data.AnnotateInventory(app.ScanAnnotationMap{
+				"0591-s00001": {{Condition: "defekt"}, {Note: "Glas gesprungen"}},
+			}, nil)
+
+			content := data.GetResultContent()
+			Expect(content[0]).To(Equal([]string{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr", "Zustand", "Hinweis"}))
+			Expect(content[1][4:]).To(Equal([]string{"defekt", "Glas gesprungen"}))
+			Expect(content[2][4:]).To(Equal([]string{"", ""}))
+
+			// the columns of a previous result are kept if the inventory is not annotated
+			data, err = app.NewInventoryData(content, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+					EquipmentNote:        "Hinweis",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data.GetResultContent()[1][4:]).To(Equal([]string{"defekt", "Glas gesprungen"}))
+		})
+
+		It("keeps existing condition and note columns of equipment without annotation", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Inventar Nr", "Zustand", "Bemerkung"},
+				{"", "1", "0591-S00001", "", "im Lager"},
+				{"", "1", "0591-S00002", "defekt", "Glas gesprungen"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			// scanner files without command barcodes
+			data.AnnotateInventory(app.ScanAnnotationMap{}, nil)
+			Expect(data.GetResultContent()[1:]).To(Equal([][]string{
+				{"", "1", "0591-S00001", "", "im Lager"},
+				{"", "1", "0591-S00002", "defekt", "Glas gesprungen"},
+			}))
+
+			data.AnnotateInventory(app.ScanAnnotationMap{"0591-s00001": {{Condition: "reparaturbedürftig"}}}, nil)
+			Expect(data.GetResultContent()[1:]).To(Equal([][]string{
+				{"", "1", "0591-S00001", "reparaturbedürftig", "im Lager"},
+				{"", "1", "0591-S00002", "defekt", "Glas gesprungen"},
+			}))
+		})
+
+		It("reports unique equipment scanned in several files as duplicates", func() {
+			logger := &utilsfakes.FakeLogger{}
+
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "1", "Handlampe", "0591-S00001"},
+				{"", "1", "Spanngurt", "0591-S00002"},
+				{"", "2", "Kabeltrommel", "0591-S00003"},
+				{"", "1", "Stromerzeuger", "0591-S00004"},
+			}
+
+			cfg := config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}
+
+			recorded := app.RecordedInventoryMap{"0591-s00001": 2, "0591-s00002": 2, "0591-s00003": 3, "0591-s00004": 3}
+			origins := app.ScanOriginsMap{
+				"0591-s00001": {{File: "scanner1.csv", Line: 4}, {File: "scanner2.csv", Line: 1}},
+				"0591-s00002": {{File: "scanner1.csv", Line: 1}, {File: "scanner1.csv", Line: 2}},
+				"0591-s00003": {{File: "scanner1.csv", Line: 3}, {File: "scanner2.csv", Line: 2}, {File: "scanner2.csv", Line: 3}},
+				"0591-s00004": {{File: "scanner1.csv", Line: 5}, {File: "scanner1.csv", Line: 6}, {File: "scanner2.csv", Line: 4}},
+			}
+
+			data, err := app.NewInventoryData(csvData, cfg, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.UpdateInventory(recorded, origins)).To(Succeed())
+
+			Expect(data.GetDuplicateScans()).To(Equal(app.DuplicateScanMap{"0591-s00001": 1, "0591-s00004": 1}))
+			Expect(data.GetSurplus()).To(Equal(app.SurplusMap{"0591-s00001": 1, "0591-s00002": 1, "0591-s00003": 1, "0591-s00004": 2}))
+
+			Expect(logger.WarnArgsForCall(0)).To(Equal("unique equipment scanned in several files, counted as surplus:"))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("0591-s00001 :          1 : scanner1.csv:4, scanner2.csv:1"))
+
+			cfg.DuplicateScanPolicy = config.DuplicateScanPolicyCap
+
+			data, err = app.NewInventoryData(csvData, cfg, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.UpdateInventory(recorded, origins)).To(Succeed())
+
+			// the repeated scan within scanner1.csv stays surplus
+			Expect(data.GetDuplicateScans()).To(Equal(app.DuplicateScanMap{"0591-s00001": 1, "0591-s00004": 1}))
+			Expect(data.GetSurplus()).To(Equal(app.SurplusMap{"0591-s00002": 1, "0591-s00003": 1, "0591-s00004": 1}))
+			Expect(data.GetContent()[1][0]).To(Equal("1"))
+			Expect(data.GetAuditLog()).To(ContainElement(app.AuditEvent{
+				ScanID: "0591-s00001", Sources: origins["0591-s00001"], NewValue: "1", Rule: "duplicate",
+			}))
+		})
+
+		It("distributes the recorded values according to the configured count distribution", func() {
+			csvData := [][]string{
+				{"Ebene", "Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"2", "", "2", "Spanngurt", "0591-S00001__1111"},
+				{"3", "", "2", "Spanngurt", "0591-S00001__1111"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				CountDistribution: config.CountDistributionDeepestLayer,
+				Columns: config.ConfigColumns{
+					EquipmentLayer:       "Ebene",
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001__1111": 3,
+			}, nil)
+
+			content := data.GetContent()
+
+			Expect(content[1][1]).To(Equal("1"))
+			Expect(content[2][1]).To(Equal("2"))
+		})
+
+		It("ignores targets which are not a number", func() {
+			logger := &utilsfakes.FakeLogger{}
+
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "viele", "Spanngurt", "0591-S00001"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001": 3,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetContent()[1][0]).To(Equal("3"))
+			Expect(data.GetSurplus()).To(BeEmpty())
+			Expect(logger.WarnCallCount()).To(Equal(1))
+			Expect(logger.WarnArgsForCall(0)).To(Equal("failed to convert column 'Menge' to number on line 2, the target is ignored"))
+		})
+
+		It("parses targets in German number format", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "1.000", "Kabelbinder", "0591-S00001__1111"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001__1111": 1001,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetContent()[1][0]).To(Equal("1000"))
+			Expect(data.GetSurplus()).To(Equal(app.SurplusMap{"0591-S00001__1111": 1}))
+		})
+
+		It("counts a measured amount completely with a single scan", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Einheit", "Ausstattung", "Inventar Nr"},
+				{"", "2,5", "m", "Seil", "0591-S00001__1111"},
+				{"", "50 m", "", "Seil", "0591-S00001__2222"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:              "Inventar Nr",
+					EquipmentCountActual:     "Verfügbar",
+					EquipmentCountTarget:     "Menge",
+					EquipmentUnit:            "Einheit",
+					EquipmentCountDifference: "Differenz",
+					EquipmentStatus:          "Status",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001__1111": 1,
+				"0591-S00001__2222": 1,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			content := data.GetResultContent()
+			Expect(content[1]).To(Equal([]string{"2,5", "2,5", "m", "Seil", "0591-S00001__1111", "0", app.StatusOK}))
+			Expect(content[2]).To(Equal([]string{"50", "50 m", "", "Seil", "0591-S00001__2222", "0", app.StatusOK}))
+			Expect(data.FindEquipment("0591-S00001__2222")[0].Unit).To(Equal("m"))
+		})
+
+		It("ignores decimal targets of pieces", func() {
+			logger := &utilsfakes.FakeLogger{}
+
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "2,5", "Spanngurt", "0591-S00001"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001": 3,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetContent()[1][0]).To(Equal("3"))
+			Expect(logger.WarnArgsForCall(0)).To(Equal("column 'Menge' on line 2 is no whole number of pieces, the target is ignored"))
+		})
+
+		It("skips rows with an invalid target if configured", func() {
+			logger := &utilsfakes.FakeLogger{}
+
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "", "Spanngurt", "0591-S00001"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				TargetPolicy: config.TargetPolicySkip,
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001": 3,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetContent()[1][0]).To(Equal(""))
+			Expect(data.GetSurplus()).To(Equal(app.SurplusMap{"0591-S00001": 3}))
+			Expect(logger.WarnArgsForCall(0)).To(Equal("failed to convert column 'Menge' to number on line 2, the line is skipped"))
+		})
+
+		It("returns an error for an invalid target if configured", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "viele", "Spanngurt", "0591-S00001"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				TargetPolicy: config.TargetPolicyError,
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001": 3,
+			}, nil)
+			Expect(err).To(MatchError("failed to convert column 'Menge' to number on line 2"))
+		})
+
+		It("records an audit event for every count update", func() {
+			csvData := [][]string{
+				{"Verfügbar", "Menge", "Ausstattung", "Inventar Nr"},
+				{"", "1", "Spanngurt", "0591-S00001__1111"},
+				{"2", "1", "Spanngurt", "0591-S00001__1111"},
+				{"", "viele", "Handlampe", "0591-S00002"},
+			}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Verfügbar",
+					EquipmentCountTarget: "Menge",
+				},
+			}, &utilsfakes.FakeLogger{})
+			Expect(err).ToNot(HaveOccurred())
+
+			sources := []app.ScanOrigin{{File: "scanner1.csv", Line: 1}, {File: "scanner1.csv", Line: 2}, {File: "scanner2.csv", Line: 1}}
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00001__1111": 3,
+			}, app.ScanOriginsMap{
+				"0591-S00001__1111": sources,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetAuditLog()).To(Equal(app.AuditLog{
+				{ScanID: "0591-S00001__1111", Sources: sources, Line: 2, OldValue: "", NewValue: "1", Rule: "fill_in_order"},
+				{ScanID: "0591-S00001__1111", Sources: sources, Line: 3, OldValue: "2", NewValue: "1", Rule: "fill_in_order"},
+				{ScanID: "0591-S00001__1111", Sources: sources, NewValue: "1", Rule: "surplus"},
+			}))
+
+			err = data.UpdateInventory(app.RecordedInventoryMap{
+				"0591-S00002": 3,
+			}, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.GetAuditLog()).To(Equal(app.AuditLog{
+				{ScanID: "0591-S00002", Line: 4, OldValue: "", NewValue: "3", Rule: "no_target"},
+			}))
+		})
+
 		It("logs not existing equipment", func() {
 			logger := &utilsfakes.FakeLogger{}
 
@@ -193,10 +694,99 @@ var _ = Describe("CSVFile", func() {
 
 			data.UpdateInventory(app.RecordedInventoryMap{
 				"not_existing": 1,
-			})
+			}, nil)
 
 			Expect(logger.WarnIndentedCallCount()).To(Equal(3))
-			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("not_existing  :     1"))
+			Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("not_existing :      1"))
+			Expect(data.GetUnknownScans()).To(Equal(app.UnknownScanMap{"not_existing": 1}))
+		})
+	})
+
+	var _ = Describe("FindEquipment", func() {
+		It("returns the rows of the equipment with their parent path", func() {
+			csvData := [][]string{
+				{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+				{"1", "Werkzeugkasten", "1111", "5678", "1", ""},
+				{"2", "Ratschenkasten", "2222", "3456", "1", ""},
+				{"3", "Ratsche", "3333", "", "1", ""},
+				{"2", "Einsatz", "4444", "", "1", ""},
+				{"3", "Nuss", "5555", "3456__5555", "2", "1"}}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentLayer:       "Ebene",
+					EquipmentPartNumber:  "Sachnummer",
+					EquipmentID:          "Inventar Nr",
+					EquipmentCountActual: "Bestand IST",
+					EquipmentCountTarget: "Menge",
+					EquipmentDescription: "Ausstattung",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(data.FindEquipment("3456__5555")).To(Equal([]app.EquipmentInfo{{
+				Line:        6,
+				Layer:       "3",
+				ID:          "3456__5555",
+				PartNumber:  "5555",
+				Description: "Nuss",
+				ParentID:    "5678",
+				ParentLine:  5,
+				ParentPath:  []string{"Werkzeugkasten", "Einsatz"},
+				Target:      "2",
+				Actual:      "1",
+			}}))
+			Expect(data.FindEquipment("unknown")).To(BeEmpty())
+		})
+
+		It("uses the part number as description if no description column is configured", func() {
+			csvData := [][]string{
+				{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+				{"1", "Werkzeugkasten", "1111", "5678"},
+				{"2", "Ratschenkasten", "2222", "3456"}}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentLayer:      "Ebene",
+					EquipmentPartNumber: "Sachnummer",
+					EquipmentID:         "Inventar Nr",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			equipment := data.FindEquipment("3456")
+			Expect(equipment).To(HaveLen(1))
+			Expect(equipment[0].Description).To(Equal("2222"))
+			Expect(equipment[0].ParentPath).To(Equal([]string{"1111"}))
+		})
+	})
+
+	var _ = Describe("GetEquipment", func() {
+		It("returns all rows with the nearest real equipment ID on the upper layers", func() {
+			csvData := [][]string{
+				{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+				{"1", "Werkzeugkasten", "1111", "5678"},
+				{"2", "Ratschenkasten", "2222", "3456"},
+				{"3", "Einsatz", "3333", "3456__3333"},
+				{"4", "Nuss", "4444", "3456__4444"}}
+
+			data, err := app.NewInventoryData(csvData, config.Config{
+				Columns: config.ConfigColumns{
+					EquipmentLayer:      "Ebene",
+					EquipmentPartNumber: "Sachnummer",
+					EquipmentID:         "Inventar Nr",
+				},
+			}, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			equipment := data.GetEquipment()
+			Expect(equipment).To(HaveLen(4))
+			Expect(equipment[0].ParentID).To(Equal(""))
+			Expect(equipment[1].ParentID).To(Equal("5678"))
+			Expect(equipment[2].ParentID).To(Equal("3456"))
+			Expect(equipment[3].ParentID).To(Equal("3456"))
+			Expect(equipment[3].Line).To(Equal(5))
+			Expect(equipment[3].ParentLine).To(Equal(4))
 		})
 	})
 
@@ -226,7 +816,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -248,7 +838,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -266,7 +856,7 @@ var _ = Describe("CSVFile", func() {
 
 			inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 			Expect(err).ToNot(HaveOccurred())
-			err = inventoryData.GeneratePsydoEquipmentIDs()
+			err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			content := inventoryData.GetContent()
@@ -290,7 +880,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -315,7 +905,7 @@ var _ = Describe("CSVFile", func() {
 
 					inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 					Expect(err).ToNot(HaveOccurred())
-					err = inventoryData.GeneratePsydoEquipmentIDs()
+					err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 					Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -339,7 +929,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -367,7 +957,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -398,7 +988,7 @@ var _ = Describe("CSVFile", func() {
 
 				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
 				Expect(err).ToNot(HaveOccurred())
-				err = inventoryData.GeneratePsydoEquipmentIDs()
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				content := inventoryData.GetContent()
@@ -423,6 +1013,123 @@ var _ = Describe("CSVFile", func() {
 				Expect(logger.InfoArgsForCall(5)).To(Equal("created ID for line 10 (processed lines 10, 7, 3)"))
 				Expect(logger.InfoArgsForCall(6)).To(Equal("created ID for line 11 (processed lines 11, 10, 7, 3)"))
 			})
+
+			It("reports IDs used by several lines", func() {
+				csvData := [][]string{
+					{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+					{"1", "Werkzugkasten", "1111", "5678"},
+					{"2", "Spanngurt", "2222", ""},
+					{"2", "Spanngurt", "2222", ""},
+					{"2", "Hammer", "3333", ""}}
+
+				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
+				Expect(err).ToNot(HaveOccurred())
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				content := inventoryData.GetContent()
+
+				Expect(content[2][3]).To(Equal("5678__2222"))
+				Expect(content[3][3]).To(Equal("5678__2222"))
+				Expect(content[4][3]).To(Equal("5678__3333"))
+
+				Expect(logger.WarnArgsForCall(0)).To(Equal("generated IDs used by several lines, enable pseudo_id.ordinal_suffix to tell them apart:"))
+				Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("5678__2222 : 3, 4"))
+			})
+
+			It("appends an ordinal to IDs used by several lines if configured", func() {
+				cfg.PseudoID = config.ConfigPseudoID{OrdinalSuffix: true}
+
+				csvData := [][]string{
+					{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+					{"1", "Werkzugkasten", "1111", "5678"},
+					{"2", "Spanngurt", "2222", ""},
+					{"2", "Hammer", "3333", ""},
+					{"2", "Spanngurt", "2222", ""}}
+
+				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
+				Expect(err).ToNot(HaveOccurred())
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				content := inventoryData.GetContent()
+
+				Expect(content[2][3]).To(Equal("5678__2222__1"))
+				Expect(content[3][3]).To(Equal("5678__3333"))
+				Expect(content[4][3]).To(Equal("5678__2222__2"))
+				Expect(logger.WarnCallCount()).To(Equal(0))
+			})
+
+			It("reuses the pinned IDs of a mapping", func() {
+				cfg.Columns.EquipmentDescription = "Ausstattung"
+
+				mapping := app.NewPseudoIDMapping()
+				mapping.Pin(app.PseudoIDIdentity{ParentID: "5678", PartNumber: "2222", Description: "Spanngurt", Ordinal: 1}, "5678__2222__7")
+
+				csvData := [][]string{
+					{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+					{"1", "Werkzugkasten", "1111", "5678"},
+					{"2", "Hammer", "3333", ""},
+					{"2", "Spanngurt", "2222", ""}}
+
+				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
+				Expect(err).ToNot(HaveOccurred())
+				err = inventoryData.GeneratePsydoEquipmentIDs(mapping)
+				Expect(err).ToNot(HaveOccurred())
+
+				content := inventoryData.GetContent()
+
+				Expect(content[2][3]).To(Equal("5678__3333"))
+				Expect(content[3][3]).To(Equal("5678__2222__7"))
+				id, ok := mapping.Lookup(app.PseudoIDIdentity{ParentID: "5678", PartNumber: "3333", Description: "Hammer", Ordinal: 1})
+				Expect(ok).To(BeTrue())
+				Expect(id).To(Equal("5678__3333"))
+				Expect(logger.WarnCallCount()).To(Equal(0))
+			})
+
+			It("does not reassign pinned IDs to other lines", func() {
+				cfg.Columns.EquipmentDescription = "Ausstattung"
+
+				mapping := app.NewPseudoIDMapping()
+				mapping.Pin(app.PseudoIDIdentity{ParentID: "5678", PartNumber: "2222", Description: "Spanngurt 5m", Ordinal: 1}, "5678__2222")
+
+				csvData := [][]string{
+					{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+					{"1", "Werkzugkasten", "1111", "5678"},
+					{"2", "Spanngurt 8m", "2222", ""}}
+
+				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
+				Expect(err).ToNot(HaveOccurred())
+				err = inventoryData.GeneratePsydoEquipmentIDs(mapping)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(inventoryData.GetContent()[2][3]).To(Equal("5678__2222__1"))
+
+				Expect(logger.WarnArgsForCall(0)).To(Equal("pseudo ID '5678__2222' is pinned to Spanngurt 5m #1 in 5678 (2222), line 3 gets '5678__2222__1'"))
+				Expect(logger.WarnArgsForCall(1)).To(Equal("pinned pseudo IDs without line in the inventory:"))
+				Expect(logger.WarnIndentedArgsForCall(2)).To(Equal("5678__2222 : Spanngurt 5m #1 in 5678 (2222)"))
+			})
+
+			It("keeps IDs generated before", func() {
+				cfg.PseudoID = config.ConfigPseudoID{Prefix: "P-"}
+
+				csvData := [][]string{
+					{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+					{"1", "Werkzugkasten", "1111", "5678"},
+					{"2", "Spanngurt", "2222", "P-5678__2222"},
+					{"2", "Hammer", "3333", ""}}
+
+				inventoryData, err := app.NewInventoryData(csvData, cfg, logger)
+				Expect(err).ToNot(HaveOccurred())
+				err = inventoryData.GeneratePsydoEquipmentIDs(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				content := inventoryData.GetContent()
+
+				Expect(content[2][3]).To(Equal("P-5678__2222"))
+				Expect(content[3][3]).To(Equal("P-5678__3333"))
+				Expect(logger.InfoCallCount()).To(Equal(1))
+			})
 		})
 	})
 })
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+func isJSONFile(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".json")
+}
+
+// ReadInventoryFile reads the inventory from a CSV file or from its JSON representation
+func ReadInventoryFile(filePath string, logger utils.Logger) (CSVContent, error) {
+	if isJSONFile(filePath) {
+		content, _, err := NewInventoryJSON(logger).Read(filePath)
+		return content, err
+	}
+
+	encoding, err := NewEncodingProvider(logger).GetFileEncoding(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encoding of file '%s': %w", filePath, err)
+	}
+
+	content, err := NewCSVFile(logger).Read(filePath, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file '%s': %v", filePath, err)
+	}
+
+	return content, nil
+}
+
+// WriteInventoryFile writes the inventory to a CSV file or to its JSON representation
+func WriteInventoryFile(filePath string, inventoryData InventoryData, config config.Config, logger utils.Logger) error {
+	if isJSONFile(filePath) {
+		return NewInventoryJSON(logger).Write(filePath, inventoryData, InventoryMetadata{
+			CreatedAt:     time.Now(),
+			InventoryFile: filepath.Base(filePath),
+		})
+	}
+
+	csvFile, err := NewOutputCSVFile(config, logger)
+	if err != nil {
+		return err
+	}
+
+	return csvFile.Write(filePath, inventoryData.GetContent())
+}
+
+// LoadInventory reads the inventory file, the inventory of the database is used instead if the file
+// is missing or was not changed since the inventory was saved to the database
+func LoadInventory(config config.Config, logger utils.Logger) (CSVContent, error) {
+	filePath := config.GetAbsoluteInventoryCSVFileName()
+
+	if databasePath := config.GetAbsoluteDatabaseFileName(); databasePath != "" {
+		store, err := OpenInventoryStore(databasePath)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+
+		content, err := store.LoadInventory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load inventory from database '%s': %w", databasePath, err)
+		}
+
+		savedAt, err := store.InventorySavedAt()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load inventory from database '%s': %w", databasePath, err)
+		}
+
+		info, statErr := os.Stat(filePath)
+
+		switch {
+		case content == nil:
+			logger.Warn(fmt.Sprintf("database '%s' contains no inventory, using '%s'", databasePath, config.InventoryCSVFileName))
+		case statErr != nil:
+			logger.Info(fmt.Sprintf("inventory file '%s' not found, using inventory from database '%s'", config.InventoryCSVFileName, databasePath))
+			return content, nil
+		case info.ModTime().After(savedAt):
+			logger.Info(fmt.Sprintf("using '%s', it was changed after the inventory was saved to database '%s'", config.InventoryCSVFileName, databasePath))
+		default:
+			logger.Info(fmt.Sprintf("using inventory from database '%s'", databasePath))
+			return content, nil
+		}
+	}
+
+	return ReadInventoryFile(filePath, logger)
+}
+
+// LoadStoredScans returns the scans saved in the database for the files which do not exist anymore,
+// e.g. a scanner file which was cleared after the import, together with the names of those files
+func LoadStoredScans(config config.Config, csvFiles []string, logger utils.Logger) ([]CSVContent, []string, error) {
+	databasePath := config.GetAbsoluteDatabaseFileName()
+	if databasePath == "" {
+		return nil, nil, nil
+	}
+
+	store, err := OpenInventoryStore(databasePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer store.Close()
+
+	fileNames, err := store.GetScanFiles()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load scans from database '%s': %w", databasePath, err)
+	}
+
+	existing := make(map[string]bool)
+	for _, file := range csvFiles {
+		existing[filepath.Base(file)] = true
+	}
+
+	var data []CSVContent
+	var names []string
+	for _, fileName := range fileNames {
+		if existing[fileName] {
+			continue
+		}
+
+		content, err := store.LoadScans(fileName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load scans of '%s' from database '%s': %w", fileName, databasePath, err)
+		}
+
+		logger.Info(fmt.Sprintf("using scans of '%s' from database '%s', the file does not exist anymore", fileName, databasePath))
+		data = append(data, content)
+		names = append(names, fileName)
+	}
+
+	return data, names, nil
+}
+
+func saveInventoryToDatabase(databasePath string, step string, content CSVContent, config config.Config, logger utils.Logger) error {
+	store, err := OpenInventoryStore(databasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	err = store.SaveInventory(content)
+	if err != nil {
+		return fmt.Errorf("failed to save inventory to database '%s': %w", databasePath, err)
+	}
+
+	now := time.Now()
+
+	err = store.SaveSession(InventorySession{
+		ID:            now.Format("2006-01-02_15-04-05"),
+		Step:          step,
+		StartedAt:     now,
+		InventoryFile: config.InventoryCSVFileName,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save session to database '%s': %w", databasePath, err)
+	}
+
+	logger.Info(fmt.Sprintf("saved inventory to database '%s'", databasePath))
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryFile", func() {
+
+	var (
+		tempDir string
+		cfg     config.Config
+		logger  *utilsfakes.FakeLogger
+	)
+
+	fileContent := app.CSVContent{{"Inventar Nr"}, {"file"}}
+	storedContent := app.CSVContent{{"Inventar Nr"}, {"database"}}
+
+	saveToDatabase := func(content app.CSVContent) {
+		store, err := app.OpenInventoryStore(filepath.Join(tempDir, "inventur.db"))
+		Expect(err).ToNot(HaveOccurred())
+		defer store.Close()
+
+		Expect(store.SaveInventory(content)).To(Succeed())
+		Expect(store.SaveScans("scan_1.csv", app.CSVContent{{"1111"}})).To(Succeed())
+		Expect(store.SaveScans("scan_2.csv", app.CSVContent{{"2222"}})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "inventory-file")
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg = config.Config{
+			WorkingDir:           tempDir,
+			InventoryCSVFileName: "inventory.csv",
+			Database:             "inventur.db",
+		}
+		logger = &utilsfakes.FakeLogger{}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("LoadInventory", func() {
+		It("should use the database if the inventory file is missing", func() {
+			saveToDatabase(storedContent)
+
+			content, err := app.LoadInventory(cfg, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(storedContent))
+			Expect(logger.InfoArgsForCall(0)).To(ContainSubstring("inventory file 'inventory.csv' not found"))
+		})
+
+		It("should use the database if the inventory file was not changed since", func() {
+			Expect(app.NewCSVFile(logger).Write(cfg.GetAbsoluteInventoryCSVFileName(), fileContent)).To(Succeed())
+			saveToDatabase(storedContent)
+
+			content, err := app.LoadInventory(cfg, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(storedContent))
+		})
+
+		It("should use the inventory file if it was changed after it was saved to the database", func() {
+			saveToDatabase(storedContent)
+
+			filePath := cfg.GetAbsoluteInventoryCSVFileName()
+			Expect(app.NewCSVFile(logger).Write(filePath, fileContent)).To(Succeed())
+			later := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(filePath, later, later)).To(Succeed())
+
+			content, err := app.LoadInventory(cfg, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(fileContent))
+			Expect(logger.InfoArgsForCall(0)).To(Equal("using 'inventory.csv', it was changed after the inventory was saved to database '" + filepath.Join(tempDir, "inventur.db") + "'"))
+		})
+	})
+
+	Describe("LoadStoredScans", func() {
+		It("should return the saved scans of the files which do not exist anymore", func() {
+			saveToDatabase(storedContent)
+
+			data, fileNames, err := app.LoadStoredScans(cfg, []string{filepath.Join(tempDir, "scan_1.csv")}, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]app.CSVContent{{{"2222"}}}))
+			Expect(fileNames).To(Equal([]string{"scan_2.csv"}))
+		})
+
+		It("should return no scans without database", func() {
+			cfg.Database = ""
+
+			data, fileNames, err := app.LoadStoredScans(cfg, nil, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(BeNil())
+			Expect(fileNames).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+const inventoryJSONVersion = 1
+
+// InventoryMetadata describes where the inventory data came from
+type InventoryMetadata struct {
+	CreatedAt     time.Time `json:"created_at"`
+	InventoryFile string    `json:"inventory_file"`
+	ScanFiles     []string  `json:"scan_files"`
+}
+
+// InventoryDocument is the canonical JSON representation of the inventory data
+type InventoryDocument struct {
+	Version  int                    `json:"version"`
+	Metadata InventoryMetadata      `json:"metadata"`
+	Header   []string               `json:"header"`
+	Rows     []InventoryDocumentRow `json:"rows"`
+}
+
+// InventoryDocumentRow holds the typed fields of a row, the raw cells in Columns are used when reading
+type InventoryDocumentRow struct {
+	Line        int               `json:"line"`
+	Layer       *int              `json:"layer"`
+	ID          string            `json:"id"`
+	PartNumber  string            `json:"part_number"`
+	Description string            `json:"description"`
+	ParentLine  int               `json:"parent_line,omitempty"`
+	ParentID    string            `json:"parent_id,omitempty"`
+	Target      *float64          `json:"target"`
+	Actual      *float64          `json:"actual"`
+	Unit        string            `json:"unit,omitempty"`
+	Columns     map[string]string `json:"columns"`
+}
+
+type InventoryJSON interface {
+	Write(filePath string, inventoryData InventoryData, metadata InventoryMetadata) error
+
+	// Read returns the content of the JSON file in the same form as read from a CSV file
+	Read(filePath string) (CSVContent, InventoryMetadata, error)
+}
+
+type inventoryJSON struct {
+	logger utils.Logger
+}
+
+func NewInventoryJSON(logger utils.Logger) InventoryJSON {
+	return &inventoryJSON{
+		logger: logger,
+	}
+}
+
+func (j *inventoryJSON) Write(filePath string, inventoryData InventoryData, metadata InventoryMetadata) error {
+	content := inventoryData.GetContent()
+	if len(content) == 0 {
+		return fmt.Errorf("failed to write JSON file '%s': inventory data is empty", filePath)
+	}
+
+	document := InventoryDocument{
+		Version:  inventoryJSONVersion,
+		Metadata: metadata,
+		Header:   content[0],
+		Rows:     []InventoryDocumentRow{},
+	}
+
+	for i, info := range inventoryData.GetEquipment() {
+		record := content[i+1]
+
+		columns := make(map[string]string)
+		for k, colName := range document.Header {
+			columns[colName] = cell(record, k)
+		}
+
+		document.Rows = append(document.Rows, InventoryDocumentRow{
+			Line:        info.Line,
+			Layer:       parseOptionalInt(info.Layer),
+			ID:          info.ID,
+			PartNumber:  info.PartNumber,
+			Description: info.Description,
+			ParentLine:  info.ParentLine,
+			ParentID:    info.ParentID,
+			Target:      parseOptionalQuantity(info.Target),
+			Actual:      parseOptionalQuantity(info.Actual),
+			Unit:        info.Unit,
+			Columns:     columns,
+		})
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal inventory data: %w", err)
+	}
+
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write JSON file '%s': %w", filePath, err)
+	}
+
+	return nil
+}
+
+func (j *inventoryJSON) Read(filePath string) (CSVContent, InventoryMetadata, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, InventoryMetadata{}, fmt.Errorf("failed to read JSON file '%s': %w", filePath, err)
+	}
+
+	var document InventoryDocument
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, InventoryMetadata{}, fmt.Errorf("failed to parse JSON file '%s': %w", filePath, err)
+	}
+
+	if document.Version != inventoryJSONVersion {
+		return nil, InventoryMetadata{}, fmt.Errorf("unsupported version %d of JSON file '%s'", document.Version, filePath)
+	}
+	if len(document.Header) == 0 {
+		return nil, InventoryMetadata{}, fmt.Errorf("JSON file '%s' has no header", filePath)
+	}
+
+	content := CSVContent{document.Header}
+	for _, row := range document.Rows {
+		record := make([]string, len(document.Header))
+		for i, colName := range document.Header {
+			record[i] = row.Columns[colName]
+		}
+		content = append(content, record)
+	}
+
+	return content, document.Metadata, nil
+}
+
+func parseOptionalInt(value string) *int {
+	result, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &result
+}
+
+func parseOptionalQuantity(value string) *float64 {
+	result, _, err := ParseQuantity(value)
+	if err != nil {
+		return nil
+	}
+	value64 := float64(result)
+	return &value64
+}
@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryJSON", func() {
+
+	var (
+		filePath      string
+		logger        *utilsfakes.FakeLogger
+		csvData       [][]string
+		inventoryData app.InventoryData
+		metadata      app.InventoryMetadata
+	)
+
+	BeforeEach(func() {
+		filePath = filepath.Join(os.TempDir(), "inventory.json")
+		logger = &utilsfakes.FakeLogger{}
+
+		csvData = [][]string{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1", "1"},
+			{"2", "Spanngurt", "2222", "5678__2222", "2", ""},
+		}
+
+		var err error
+		inventoryData, err = app.NewInventoryData(csvData, config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+				EquipmentCountTarget: "Menge",
+				EquipmentDescription: "Ausstattung",
+			},
+		}, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		metadata = app.InventoryMetadata{
+			CreatedAt:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			InventoryFile: "inventory.csv",
+			ScanFiles:     []string{"scanner1.csv"},
+		}
+	})
+
+	AfterEach(func() {
+		os.Remove(filePath)
+	})
+
+	It("writes the rows with typed fields and the hierarchy", func() {
+		err := app.NewInventoryJSON(logger).Write(filePath, inventoryData, metadata)
+		Expect(err).ToNot(HaveOccurred())
+
+		data, err := os.ReadFile(filePath)
+		Expect(err).ToNot(HaveOccurred())
+
+		var document app.InventoryDocument
+		Expect(json.Unmarshal(data, &document)).To(Succeed())
+
+		Expect(document.Version).To(Equal(1))
+		Expect(document.Metadata).To(Equal(metadata))
+		Expect(document.Header).To(Equal(csvData[0]))
+		Expect(document.Rows).To(HaveLen(2))
+
+		row := document.Rows[1]
+		Expect(row.Line).To(Equal(3))
+		Expect(*row.Layer).To(Equal(2))
+		Expect(row.ID).To(Equal("5678__2222"))
+		Expect(row.PartNumber).To(Equal("2222"))
+		Expect(row.Description).To(Equal("Spanngurt"))
+		Expect(row.ParentLine).To(Equal(2))
+		Expect(row.ParentID).To(Equal("5678"))
+		Expect(*row.Target).To(Equal(2.0))
+		Expect(row.Actual).To(BeNil())
+		Expect(row.Columns).To(HaveKeyWithValue("Ausstattung", "Spanngurt"))
+	})
+
+	It("reads the content written before", func() {
+		jsonFile := app.NewInventoryJSON(logger)
+
+		err := jsonFile.Write(filePath, inventoryData, metadata)
+		Expect(err).ToNot(HaveOccurred())
+
+		content, readMetadata, err := jsonFile.Read(filePath)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(content).To(Equal(app.CSVContent(csvData)))
+		Expect(readMetadata).To(Equal(metadata))
+	})
+
+	It("returns an error for an unsupported version", func() {
+		Expect(os.WriteFile(filePath, []byte(`{"version": 2, "header": ["Ebene"]}`), 0644)).To(Succeed())
+
+		_, _, err := app.NewInventoryJSON(logger).Read(filePath)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unsupported version 2"))
+	})
+})
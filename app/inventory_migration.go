@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+// MigrationReport lists the lines which could not be matched between the previous inventory and the new export
+type MigrationReport struct {
+	Migrated int
+
+	// Unmatched are lines of the previous inventory with actual counts which are missing in the new export
+	Unmatched []EquipmentInfo
+
+	// Added are lines of the new export which are missing in the previous inventory
+	Added []EquipmentInfo
+}
+
+type InventoryMigration interface {
+	// Migrate initializes the new export with the pseudo IDs and actual counts of the previous inventory
+	Migrate(previous CSVContent, export CSVContent, mapping PseudoIDMapping) (InventoryData, MigrationReport, error)
+}
+
+type inventoryMigration struct {
+	config config.Config
+	logger utils.Logger
+}
+
+func NewInventoryMigration(config config.Config, logger utils.Logger) InventoryMigration {
+	return &inventoryMigration{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (m *inventoryMigration) Migrate(previous CSVContent, export CSVContent, mapping PseudoIDMapping) (InventoryData, MigrationReport, error) {
+	var report MigrationReport
+
+	previousData, err := NewInventoryData(previous, m.config, m.logger)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to init previous inventory data: %v", err)
+	}
+
+	if mapping == nil {
+		mapping = NewPseudoIDMapping()
+	}
+
+	err = m.pinPreviousPseudoIDs(previousData, mapping)
+	if err != nil {
+		return nil, report, err
+	}
+
+	exportData, err := NewInventoryData(export, m.config, m.logger)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to init inventory data: %v", err)
+	}
+
+	exportData.AddActualColumn()
+
+	err = exportData.GeneratePsydoEquipmentIDs(mapping)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to generate pseudo IDs: %w", err)
+	}
+
+	// lines sharing an ID are matched in file order
+	previousLines := make(map[string][]EquipmentInfo)
+	for _, info := range previousData.GetEquipment() {
+		id := strings.ToLower(info.ID)
+		previousLines[id] = append(previousLines[id], info)
+	}
+
+	content := exportData.GetContent()
+	columns := exportData.Columns()
+	idIndex := indexOf(content[0], columns.EquipmentID)
+	actualIndex := indexOf(content[0], columns.EquipmentCountActual)
+
+	matched := make(map[string]int)
+	var added []int
+
+	for i, record := range content[1:] {
+		id := strings.ToLower(cell(record, idIndex))
+
+		if id == "" || matched[id] >= len(previousLines[id]) {
+			added = append(added, i)
+			continue
+		}
+
+		record[actualIndex] = previousLines[id][matched[id]].Actual
+		matched[id]++
+		report.Migrated++
+	}
+
+	for _, info := range previousData.GetEquipment() {
+		id := strings.ToLower(info.ID)
+
+		// the first lines of an ID are the matched ones
+		if matched[id] > 0 {
+			matched[id]--
+			continue
+		}
+		if strings.TrimSpace(info.Actual) != "" {
+			report.Unmatched = append(report.Unmatched, info)
+		}
+	}
+
+	migratedData, err := NewInventoryData(content, m.config, m.logger)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to init inventory data: %v", err)
+	}
+
+	equipment := migratedData.GetEquipment()
+	for _, i := range added {
+		report.Added = append(report.Added, equipment[i])
+	}
+
+	return migratedData, report, nil
+}
+
+// pinPreviousPseudoIDs keeps the pseudo IDs of the previous inventory for the same lines of the new export
+func (m *inventoryMigration) pinPreviousPseudoIDs(previousData InventoryData, mapping PseudoIDMapping) error {
+	pseudoIDs, err := NewPseudoIDScheme(m.config)
+	if err != nil {
+		return err
+	}
+
+	ordinals := make(map[PseudoIDIdentity]int)
+
+	for _, info := range previousData.GetEquipment() {
+		if !pseudoIDs.IsPseudoID(info.ID) {
+			continue
+		}
+
+		identity := PseudoIDIdentity{
+			ParentID:    info.ParentID,
+			PartNumber:  info.PartNumber,
+			Description: info.Description,
+		}
+		ordinals[identity]++
+		identity.Ordinal = ordinals[identity]
+
+		if _, pinned := mapping.Lookup(identity); pinned {
+			continue
+		}
+		if _, owned := mapping.Owner(info.ID); owned {
+			continue
+		}
+
+		mapping.Pin(identity, info.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryMigration", func() {
+
+	var (
+		cfg       config.Config
+		migration app.InventoryMigration
+	)
+
+	BeforeEach(func() {
+		cfg = config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentDescription: "Ausstattung",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+			},
+		}
+		migration = app.NewInventoryMigration(cfg, &utilsfakes.FakeLogger{})
+	})
+
+	It("should carry over the actual counts and pseudo IDs", func() {
+		previous := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1"},
+			{"2", "Spanngurt", "2222", "5678__2222", "2"},
+			{"2", "Spanngurt", "2222", "5678__2222", "1"},
+			{"1", "Handlampe", "3333", "9012", "1"},
+		}
+
+		export := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+			{"1", "Handlampe", "3333", "9012"},
+			{"1", "Werkzeugkasten", "1111", "5678"},
+			{"2", "Spanngurt", "2222", ""},
+			{"2", "Spanngurt", "2222", ""},
+			{"2", "Hammer", "4444", ""},
+		}
+
+		inventoryData, report, err := migration.Migrate(previous, export, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(inventoryData.GetContent()).To(Equal([][]string{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "Handlampe", "3333", "9012", "1"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1"},
+			{"2", "Spanngurt", "2222", "5678__2222", "2"},
+			{"2", "Spanngurt", "2222", "5678__2222", "1"},
+			{"2", "Hammer", "4444", "5678__4444", ""},
+		}))
+
+		Expect(report.Migrated).To(Equal(4))
+		Expect(report.Unmatched).To(BeEmpty())
+		Expect(report.Added).To(HaveLen(1))
+		Expect(report.Added[0].Line).To(Equal(6))
+		Expect(report.Added[0].ID).To(Equal("5678__4444"))
+	})
+
+	It("should keep pseudo IDs with another scheme", func() {
+		cfg.PseudoID = config.ConfigPseudoID{Prefix: "P-", OrdinalSuffix: true}
+		migration = app.NewInventoryMigration(cfg, &utilsfakes.FakeLogger{})
+
+		previous := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", ""},
+			{"2", "Spanngurt", "2222", "P-5678__2222__9", "3"},
+		}
+
+		export := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+			{"1", "Werkzeugkasten", "1111", "5678"},
+			{"2", "Spanngurt", "2222", ""},
+		}
+
+		inventoryData, _, err := migration.Migrate(previous, export, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(inventoryData.GetContent()[2]).To(Equal([]string{"2", "Spanngurt", "2222", "P-5678__2222__9", "3"}))
+	})
+
+	It("should report counted lines missing in the new export", func() {
+		previous := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1"},
+			{"1", "Handlampe", "3333", "9012", "1"},
+			{"1", "Fuchsschwanz", "4444", "3456", ""},
+		}
+
+		export := app.CSVContent{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr"},
+			{"1", "Werkzeugkasten", "1111", "5678"},
+		}
+
+		_, report, err := migration.Migrate(previous, export, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Migrated).To(Equal(1))
+		Expect(report.Unmatched).To(HaveLen(1))
+		Expect(report.Unmatched[0].ID).To(Equal("9012"))
+		Expect(report.Unmatched[0].Line).To(Equal(3))
+		Expect(report.Added).To(BeEmpty())
+	})
+})
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+// InventoryCounts sums up the progress of a set of rows, rows without valid target are not included
+type InventoryCounts struct {
+	RowsWithTarget   int `json:"rows_with_target"`
+	FullyCounted     int `json:"fully_counted"`
+	PartiallyCounted int `json:"partially_counted"`
+	NotCounted       int `json:"not_counted"`
+
+	// a measured amount like meters of a rope counts as a single item
+	Items        int `json:"items"`
+	CountedItems int `json:"counted_items"`
+}
+
+// Coverage returns the percentage of the counted items
+func (c InventoryCounts) Coverage() float64 {
+	return percentage(c.CountedItems, c.Items)
+}
+
+func (c *InventoryCounts) add(target Quantity, actual Quantity, unit string) {
+	c.RowsWithTarget++
+
+	switch {
+	case actual >= target:
+		c.FullyCounted++
+	case actual > 0:
+		c.PartiallyCounted++
+	default:
+		c.NotCounted++
+	}
+
+	if !IsPieceUnit(unit) {
+		c.Items++
+		if actual >= target {
+			c.CountedItems++
+		}
+		return
+	}
+	c.Items += int(target)
+	c.CountedItems += int(max(min(actual, target), 0))
+}
+
+// InventoryCoverage is the progress of a group of rows, e.g. a top-level layer
+type InventoryCoverage struct {
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+	InventoryCounts
+}
+
+// InventoryStatistics is the summary of an inventory result
+type InventoryStatistics struct {
+	Rows int `json:"rows"`
+	InventoryCounts
+
+	// UnknownScans is the amount of scans without line in the inventory
+	UnknownScans int `json:"unknown_scans"`
+
+	// MalformedScans is the amount of scans not matching the configured ID formats, those are not unknown scans
+	MalformedScans int `json:"malformed_scans"`
+
+	// Layers are the top-level layers in file order
+	Layers []InventoryCoverage `json:"layers"`
+
+	// PartNumbers are the groups of rows with the same part number sorted by part number
+	PartNumbers []InventoryCoverage `json:"part_numbers"`
+}
+
+// NewInventoryStatistics computes the statistics of the equipment, without target column each row has a target of 1
+func NewInventoryStatistics(equipment []EquipmentInfo, unknownScans UnknownScanMap, config config.Config) InventoryStatistics {
+	statistics := InventoryStatistics{
+		Rows:        len(equipment),
+		Layers:      []InventoryCoverage{},
+		PartNumbers: []InventoryCoverage{},
+	}
+
+	for _, amount := range unknownScans {
+		statistics.UnknownScans += amount
+	}
+
+	partNumbers := make(map[string]*InventoryCoverage)
+
+	for _, info := range equipment {
+		// the rows below a top-level row follow it
+		if len(info.ParentPath) == 0 {
+			statistics.Layers = append(statistics.Layers, InventoryCoverage{Name: info.Description, ID: info.ID})
+		}
+
+		target, actual := Quantity(1), Quantity(0)
+		if config.Columns.EquipmentCountTarget != "" {
+			value, _, err := ParseQuantity(info.Target)
+			if err != nil || value <= 0 {
+				continue
+			}
+			target = value
+		}
+		if value, _, err := ParseQuantity(info.Actual); err == nil {
+			actual = value
+		}
+
+		statistics.add(target, actual, info.Unit)
+
+		if len(statistics.Layers) > 0 {
+			statistics.Layers[len(statistics.Layers)-1].add(target, actual, info.Unit)
+		}
+
+		if info.PartNumber != "" {
+			group, ok := partNumbers[info.PartNumber]
+			if !ok {
+				group = &InventoryCoverage{Name: info.PartNumber}
+				partNumbers[info.PartNumber] = group
+			}
+			group.add(target, actual, info.Unit)
+		}
+	}
+
+	for _, group := range partNumbers {
+		statistics.PartNumbers = append(statistics.PartNumbers, *group)
+	}
+	sort.Slice(statistics.PartNumbers, func(i, j int) bool {
+		return statistics.PartNumbers[i].Name < statistics.PartNumbers[j].Name
+	})
+
+	return statistics
+}
+
+func (s InventoryStatistics) Log(logger utils.Logger) {
+	logger.Info("inventory statistics:")
+	logger.Info("")
+	logger.InfoIndented(fmt.Sprintf("rows                          : %5d", s.Rows))
+	logger.InfoIndented(fmt.Sprintf("rows with target              : %5d", s.RowsWithTarget))
+	logger.InfoIndented(fmt.Sprintf("rows fully counted            : %5d", s.FullyCounted))
+	logger.InfoIndented(fmt.Sprintf("rows partially counted        : %5d", s.PartiallyCounted))
+	logger.InfoIndented(fmt.Sprintf("rows not counted              : %5d", s.NotCounted))
+	logger.InfoIndented(fmt.Sprintf("items counted                 : %5d / %5d (%5.1f %%)", s.CountedItems, s.Items, s.Coverage()))
+	logger.InfoIndented(fmt.Sprintf("unknown scans                 : %5d", s.UnknownScans))
+	logger.InfoIndented(fmt.Sprintf("malformed scans               : %5d", s.MalformedScans))
+	logger.Info("")
+
+	layers := ReportTable{
+		Title: "coverage per top-level layer:",
+		Columns: []ReportColumn{
+			{Header: "layer"},
+			{Header: "equipment"},
+			{Header: "rows", AlignRight: true},
+			{Header: "partial", AlignRight: true},
+			{Header: "open", AlignRight: true},
+			{Header: "coverage", AlignRight: true},
+		},
+	}
+	for _, layer := range s.Layers {
+		layers.Rows = append(layers.Rows, []string{
+			layer.Name,
+			layer.ID,
+			strconv.Itoa(layer.RowsWithTarget),
+			strconv.Itoa(layer.PartiallyCounted),
+			strconv.Itoa(layer.NotCounted),
+			fmt.Sprintf("%.1f %%", layer.Coverage()),
+		})
+	}
+
+	partNumbers := ReportTable{
+		Title: "coverage per part number:",
+		Columns: []ReportColumn{
+			{Header: "part number"},
+			{Header: "rows", AlignRight: true},
+			{Header: "partial", AlignRight: true},
+			{Header: "open", AlignRight: true},
+			{Header: "coverage", AlignRight: true},
+		},
+	}
+	for _, group := range s.PartNumbers {
+		partNumbers.Rows = append(partNumbers.Rows, []string{
+			group.Name,
+			strconv.Itoa(group.RowsWithTarget),
+			strconv.Itoa(group.PartiallyCounted),
+			strconv.Itoa(group.NotCounted),
+			fmt.Sprintf("%.1f %%", group.Coverage()),
+		})
+	}
+
+	reporter := NewReporter(logger)
+	reporter.Table(layers)
+	reporter.Table(partNumbers)
+}
+
+func (s InventoryStatistics) WriteJSON(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal inventory statistics: %w", err)
+	}
+
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write inventory statistics '%s': %w", filePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryStatistics", func() {
+
+	var (
+		logger *utilsfakes.FakeLogger
+		cfg    config.Config
+	)
+
+	BeforeEach(func() {
+		logger = &utilsfakes.FakeLogger{}
+		cfg = config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+				EquipmentCountTarget: "Menge",
+				EquipmentDescription: "Ausstattung",
+			},
+		}
+	})
+
+	It("should sum up the progress per top-level layer and part number", func() {
+		inventoryData, err := app.NewInventoryData([][]string{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1", "1"},
+			{"2", "Spanngurt", "2222", "5678__2222", "3", "2"},
+			{"2", "Seil", "4444", "5678__4444", "20 m", "20 m"},
+			{"1", "Rettungsweste", "5555", "5680", "1", ""},
+			{"2", "Spanngurt", "2222", "5680__2222", "2", "2"},
+			{"2", "Einsatz", "6666", "5681", "", ""},
+		}, cfg, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		statistics := app.NewInventoryStatistics(inventoryData.GetEquipment(), app.UnknownScanMap{"9999": 2, "8888": 1}, cfg)
+
+		Expect(statistics.Rows).To(Equal(6))
+		Expect(statistics.InventoryCounts).To(Equal(app.InventoryCounts{
+			RowsWithTarget:   5,
+			FullyCounted:     3,
+			PartiallyCounted: 1,
+			NotCounted:       1,
+			Items:            8,
+			CountedItems:     6,
+		}))
+		Expect(statistics.UnknownScans).To(Equal(3))
+
+		Expect(statistics.Layers).To(Equal([]app.InventoryCoverage{
+			{Name: "Werkzeugkasten", ID: "5678", InventoryCounts: app.InventoryCounts{RowsWithTarget: 3, FullyCounted: 2, PartiallyCounted: 1, Items: 5, CountedItems: 4}},
+			{Name: "Rettungsweste", ID: "5680", InventoryCounts: app.InventoryCounts{RowsWithTarget: 2, FullyCounted: 1, NotCounted: 1, Items: 3, CountedItems: 2}},
+		}))
+
+		Expect(statistics.PartNumbers).To(HaveLen(4))
+		Expect(statistics.PartNumbers[1]).To(Equal(app.InventoryCoverage{
+			Name:            "2222",
+			InventoryCounts: app.InventoryCounts{RowsWithTarget: 2, FullyCounted: 1, PartiallyCounted: 1, Items: 5, CountedItems: 4},
+		}))
+		Expect(statistics.Coverage()).To(Equal(75.0))
+	})
+
+	It("should log the coverage per top-level layer and part number", func() {
+		statistics := app.InventoryStatistics{
+			Layers: []app.InventoryCoverage{
+				{Name: "Werkzeugkasten", ID: "5678", InventoryCounts: app.InventoryCounts{RowsWithTarget: 3, PartiallyCounted: 1, Items: 5, CountedItems: 4}},
+			},
+			PartNumbers: []app.InventoryCoverage{
+				{Name: "1111", InventoryCounts: app.InventoryCounts{RowsWithTarget: 1, Items: 1, CountedItems: 1}},
+				{Name: "2222", InventoryCounts: app.InventoryCounts{RowsWithTarget: 2, PartiallyCounted: 1, NotCounted: 1, Items: 4, CountedItems: 1}},
+			},
+		}
+
+		statistics.Log(logger)
+
+		var lines []string
+		for i := 0; i < logger.InfoIndentedCallCount(); i++ {
+			lines = append(lines, logger.InfoIndentedArgsForCall(i))
+		}
+		Expect(lines[len(lines)-7:]).To(Equal([]string{
+			"layer          : equipment : rows : partial : open : coverage",
+			"-------------------------------------------------------------",
+			"Werkzeugkasten : 5678      :    3 :       1 :    0 :   80.0 %",
+			"part number : rows : partial : open : coverage",
+			"----------------------------------------------",
+			"1111        :    1 :       0 :    0 :  100.0 %",
+			"2222        :    2 :       1 :    1 :   25.0 %",
+		}))
+	})
+
+	It("should count each row once without target column", func() {
+		cfg.Columns.EquipmentCountTarget = ""
+
+		inventoryData, err := app.NewInventoryData([][]string{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1"},
+			{"2", "Handlampe", "3333", "5679", ""},
+		}, cfg, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		statistics := app.NewInventoryStatistics(inventoryData.GetEquipment(), nil, cfg)
+
+		Expect(statistics.InventoryCounts).To(Equal(app.InventoryCounts{
+			RowsWithTarget: 2,
+			FullyCounted:   1,
+			NotCounted:     1,
+			Items:          2,
+			CountedItems:   1,
+		}))
+	})
+})
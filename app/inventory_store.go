@@ -0,0 +1,278 @@
+package app
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	inventoryBucket = []byte("inventory")
+	scansBucket     = []byte("scans")
+	sessionsBucket  = []byte("sessions")
+	resultsBucket   = []byte("results")
+	metadataBucket  = []byte("metadata")
+
+	inventorySavedAtKey = []byte("inventory_saved_at")
+)
+
+// InventorySession describes a single run of a step which used the store
+type InventorySession struct {
+	ID            string    `json:"id"`
+	Step          string    `json:"step"`
+	StartedAt     time.Time `json:"started_at"`
+	InventoryFile string    `json:"inventory_file"`
+	ScanFiles     []string  `json:"scan_files"`
+}
+
+// InventoryStore keeps the inventory, the scans, the sessions and their results in a single database file
+type InventoryStore interface {
+	SaveInventory(content CSVContent) error
+
+	// LoadInventory returns nil if no inventory was saved yet
+	LoadInventory() (CSVContent, error)
+
+	// InventorySavedAt returns the zero time if the inventory was saved by a previous version or not at all
+	InventorySavedAt() (time.Time, error)
+
+	SaveScans(fileName string, content CSVContent) error
+
+	LoadScans(fileName string) (CSVContent, error)
+
+	// GetScanFiles returns the names of the files with saved scans in alphabetical order
+	GetScanFiles() ([]string, error)
+
+	SaveSession(session InventorySession, result CSVContent) error
+
+	GetSessions() ([]InventorySession, error)
+
+	LoadResult(sessionID string) (CSVContent, error)
+
+	Close() error
+}
+
+type inventoryStore struct {
+	db *bolt.DB
+}
+
+func OpenInventoryStore(filePath string) (InventoryStore, error) {
+	db, err := bolt.Open(filePath, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database '%s': %w", filePath, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{inventoryBucket, scansBucket, sessionsBucket, resultsBucket, metadataBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init database '%s': %w", filePath, err)
+	}
+
+	return &inventoryStore{
+		db: db,
+	}, nil
+}
+
+func (s *inventoryStore) SaveInventory(content CSVContent) error {
+	savedAt, err := time.Now().MarshalText()
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(metadataBucket).Put(inventorySavedAtKey, savedAt)
+		if err != nil {
+			return err
+		}
+		return replaceRows(tx.Bucket(inventoryBucket), content)
+	})
+}
+
+func (s *inventoryStore) InventorySavedAt() (time.Time, error) {
+	var savedAt time.Time
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(metadataBucket).Get(inventorySavedAtKey)
+		if value == nil {
+			return nil
+		}
+		return savedAt.UnmarshalText(value)
+	})
+
+	return savedAt, err
+}
+
+func (s *inventoryStore) LoadInventory() (CSVContent, error) {
+	var content CSVContent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		content, err = readRows(tx.Bucket(inventoryBucket))
+		return err
+	})
+
+	return content, err
+}
+
+func (s *inventoryStore) SaveScans(fileName string, content CSVContent) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(scansBucket).CreateBucketIfNotExists([]byte(fileName))
+		if err != nil {
+			return err
+		}
+		return replaceRows(bucket, content)
+	})
+}
+
+func (s *inventoryStore) LoadScans(fileName string) (CSVContent, error) {
+	var content CSVContent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(scansBucket).Bucket([]byte(fileName))
+		if bucket == nil {
+			return nil
+		}
+
+		var err error
+		content, err = readRows(bucket)
+		return err
+	})
+
+	return content, err
+}
+
+func (s *inventoryStore) GetScanFiles() ([]string, error) {
+	var fileNames []string
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(scansBucket).ForEach(func(key, value []byte) error {
+			// nested buckets have no value
+			if value == nil {
+				fileNames = append(fileNames, string(key))
+			}
+			return nil
+		})
+	})
+
+	return fileNames, err
+}
+
+func (s *inventoryStore) SaveSession(session InventorySession, result CSVContent) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(sessionsBucket).Put([]byte(session.ID), data)
+		if err != nil {
+			return err
+		}
+
+		if result == nil {
+			return nil
+		}
+
+		bucket, err := tx.Bucket(resultsBucket).CreateBucketIfNotExists([]byte(session.ID))
+		if err != nil {
+			return err
+		}
+		return replaceRows(bucket, result)
+	})
+}
+
+func (s *inventoryStore) GetSessions() ([]InventorySession, error) {
+	var sessions []InventorySession
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(_, value []byte) error {
+			var session InventorySession
+			if err := json.Unmarshal(value, &session); err != nil {
+				return err
+			}
+			sessions = append(sessions, session)
+			return nil
+		})
+	})
+
+	return sessions, err
+}
+
+func (s *inventoryStore) LoadResult(sessionID string) (CSVContent, error) {
+	var content CSVContent
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(resultsBucket).Bucket([]byte(sessionID))
+		if bucket == nil {
+			return fmt.Errorf("no result found for session '%s'", sessionID)
+		}
+
+		var err error
+		content, err = readRows(bucket)
+		return err
+	})
+
+	return content, err
+}
+
+func (s *inventoryStore) Close() error {
+	return s.db.Close()
+}
+
+// replaceRows stores each row with its index as key, so the cursor returns them in order
+func replaceRows(bucket *bolt.Bucket, content CSVContent) error {
+	var keys [][]byte
+	err := bucket.ForEach(func(key, _ []byte) error {
+		keys = append(keys, append([]byte{}, key...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	for i, record := range content {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(i))
+
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readRows(bucket *bolt.Bucket) (CSVContent, error) {
+	var content CSVContent
+
+	err := bucket.ForEach(func(_, value []byte) error {
+		var record []string
+		if err := json.Unmarshal(value, &record); err != nil {
+			return err
+		}
+		content = append(content, record)
+		return nil
+	})
+
+	return content, err
+}
@@ -0,0 +1,123 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryStore", func() {
+
+	var (
+		tempDir string
+		store   app.InventoryStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "inventory-store")
+		Expect(err).ToNot(HaveOccurred())
+
+		store, err = app.OpenInventoryStore(filepath.Join(tempDir, "inventur.db"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		store.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	It("should return no inventory if none was saved", func() {
+		content, err := store.LoadInventory()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(BeNil())
+	})
+
+	It("should save and load the inventory in order", func() {
+		content := app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr"},
+			{"1", "1111", "5678"},
+			{"2", "2222", ""},
+		}
+		Expect(store.SaveInventory(content)).To(Succeed())
+
+		loaded, err := store.LoadInventory()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(content))
+	})
+
+	It("should replace a previously saved inventory", func() {
+		Expect(store.SaveInventory(app.CSVContent{{"a"}, {"b"}, {"c"}})).To(Succeed())
+		Expect(store.SaveInventory(app.CSVContent{{"d"}})).To(Succeed())
+
+		loaded, err := store.LoadInventory()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(app.CSVContent{{"d"}}))
+	})
+
+	It("should save and load the scans by file name", func() {
+		Expect(store.SaveScans("scan_1.csv", app.CSVContent{{"5678"}, {"1111"}})).To(Succeed())
+		Expect(store.SaveScans("scan_2.csv", app.CSVContent{{"2222"}})).To(Succeed())
+
+		loaded, err := store.LoadScans("scan_1.csv")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(app.CSVContent{{"5678"}, {"1111"}}))
+
+		loaded, err = store.LoadScans("unknown.csv")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(BeNil())
+	})
+
+	It("should record when the inventory was saved", func() {
+		savedAt, err := store.InventorySavedAt()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(savedAt.IsZero()).To(BeTrue())
+
+		before := time.Now()
+		Expect(store.SaveInventory(app.CSVContent{{"a"}})).To(Succeed())
+
+		savedAt, err = store.InventorySavedAt()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(savedAt).To(BeTemporally(">=", before))
+	})
+
+	It("should list the files with saved scans", func() {
+		Expect(store.SaveScans("scan_2.csv", app.CSVContent{{"2222"}})).To(Succeed())
+		Expect(store.SaveScans("scan_1.csv", app.CSVContent{{"1111"}})).To(Succeed())
+
+		fileNames, err := store.GetScanFiles()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fileNames).To(Equal([]string{"scan_1.csv", "scan_2.csv"}))
+	})
+
+	It("should save the sessions with their results", func() {
+		startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		session := app.InventorySession{
+			ID:            "2024-01-02_03-04-05",
+			Step:          "process",
+			StartedAt:     startedAt,
+			InventoryFile: "inventory.csv",
+			ScanFiles:     []string{"scan_1.csv"},
+		}
+		result := app.CSVContent{{"Inventar Nr", "Bestand IST"}, {"5678", "1"}}
+
+		Expect(store.SaveSession(session, result)).To(Succeed())
+		Expect(store.SaveSession(app.InventorySession{ID: "init", Step: "init", StartedAt: startedAt}, nil)).To(Succeed())
+
+		sessions, err := store.GetSessions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sessions).To(HaveLen(2))
+		Expect(sessions).To(ContainElement(session))
+
+		loaded, err := store.LoadResult(session.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(result))
+
+		_, err = store.LoadResult("init")
+		Expect(err).To(MatchError("no result found for session 'init'"))
+	})
+})
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+type ValidationCategory string
+
+const (
+	ValidationMissingColumn   ValidationCategory = "missing column"
+	ValidationShortRow        ValidationCategory = "row shorter than header"
+	ValidationDuplicateID     ValidationCategory = "duplicate inventory number"
+	ValidationInvalidLayer    ValidationCategory = "invalid layer"
+	ValidationLayerJump       ValidationCategory = "layer jump"
+	ValidationInvalidTarget   ValidationCategory = "invalid target"
+	ValidationEmptyPartNumber ValidationCategory = "empty part number"
+)
+
+// validationCategories defines the order of the categories in the report
+var validationCategories = []ValidationCategory{
+	ValidationMissingColumn,
+	ValidationShortRow,
+	ValidationDuplicateID,
+	ValidationInvalidLayer,
+	ValidationLayerJump,
+	ValidationInvalidTarget,
+	ValidationEmptyPartNumber,
+}
+
+// ValidationFinding describes a problem on a line of the inventory file
+type ValidationFinding struct {
+	Category ValidationCategory
+	Line     int
+	Message  string
+}
+
+type ValidationReport []ValidationFinding
+
+// Log prints the findings grouped by category
+func (r ValidationReport) Log(logger utils.Logger) {
+	if len(r) == 0 {
+		logger.Info("no problems found")
+		return
+	}
+
+	logger.Warn(fmt.Sprintf("found %d problems:", len(r)))
+	for _, category := range validationCategories {
+		var findings []ValidationFinding
+		for _, finding := range r {
+			if finding.Category == category {
+				findings = append(findings, finding)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		logger.Warn("")
+		logger.Warn(fmt.Sprintf("%s (%d):", category, len(findings)))
+		for _, finding := range findings {
+			logger.WarnIndented(fmt.Sprintf("line %5d: %s", finding.Line, finding.Message))
+		}
+	}
+	logger.Warn("")
+}
+
+type InventoryValidator interface {
+	// Validate checks the content of an inventory file against the configured columns
+	Validate(content CSVContent) ValidationReport
+}
+
+type inventoryValidator struct {
+	config    config.Config
+	pseudoIDs PseudoIDScheme
+	logger    utils.Logger
+}
+
+func NewInventoryValidator(config config.Config, logger utils.Logger) (InventoryValidator, error) {
+	pseudoIDs, err := NewPseudoIDScheme(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inventoryValidator{
+		config:    config,
+		pseudoIDs: pseudoIDs,
+		logger:    logger,
+	}, nil
+}
+
+func (v *inventoryValidator) Validate(content CSVContent) ValidationReport {
+	var report ValidationReport
+
+	if len(content) == 0 {
+		return append(report, ValidationFinding{ValidationMissingColumn, 1, "the file has no header"})
+	}
+
+	header := content[0]
+
+	// the actual column is not required, it is added by the init step
+	inventoryData, err := NewInventoryData(content, v.config, v.logger)
+
+	var missingColumns *config.MissingColumnsError
+	if errors.As(err, &missingColumns) {
+		for _, column := range missingColumns.Columns {
+			report = append(report, ValidationFinding{ValidationMissingColumn, 1, fmt.Sprintf("%s not found in header", column)})
+		}
+		return report
+	}
+	if err != nil {
+		return append(report, ValidationFinding{ValidationMissingColumn, 1, err.Error()})
+	}
+
+	columns := inventoryData.Columns()
+
+	layerIndex := indexOf(header, columns.EquipmentLayer)
+	partNumberIndex := indexOf(header, columns.EquipmentPartNumber)
+	idIndex := indexOf(header, columns.EquipmentID)
+	targetIndex := indexOf(header, columns.EquipmentCountTarget)
+
+	idLines := make(map[string]int)
+	previousLayer := 0
+
+	for i, record := range content[1:] {
+		line := i + 2
+
+		if len(record) < len(header) {
+			report = append(report, ValidationFinding{ValidationShortRow, line, fmt.Sprintf("%d of %d columns", len(record), len(header))})
+		}
+
+		id := strings.TrimSpace(cell(record, idIndex))
+		if v.pseudoIDs.IsRealID(id) {
+			if firstLine, ok := idLines[id]; ok {
+				report = append(report, ValidationFinding{ValidationDuplicateID, line, fmt.Sprintf("'%s' already used on line %d", id, firstLine)})
+			} else {
+				idLines[id] = line
+			}
+		}
+
+		layerValue := cell(record, layerIndex)
+		layer, err := strconv.Atoi(strings.TrimSpace(layerValue))
+		if err != nil {
+			report = append(report, ValidationFinding{ValidationInvalidLayer, line, fmt.Sprintf("'%s' is not a number", layerValue)})
+		} else {
+			if layer > previousLayer+1 {
+				report = append(report, ValidationFinding{ValidationLayerJump, line, fmt.Sprintf("layer %d follows layer %d", layer, previousLayer)})
+			}
+			previousLayer = layer
+		}
+
+		if targetIndex >= 0 {
+			targetValue := cell(record, targetIndex)
+			if _, _, err := ParseQuantity(targetValue); err != nil {
+				report = append(report, ValidationFinding{ValidationInvalidTarget, line, fmt.Sprintf("'%s' is not a number", targetValue)})
+			}
+		}
+
+		// rows without inventory number get a pseudo ID created from the part number
+		if !v.pseudoIDs.IsRealID(id) && !v.pseudoIDs.IsPseudoID(id) && strings.TrimSpace(cell(record, partNumberIndex)) == "" {
+			report = append(report, ValidationFinding{ValidationEmptyPartNumber, line, "no inventory number and no part number"})
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,89 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryValidator", func() {
+
+	var validator app.InventoryValidator
+
+	BeforeEach(func() {
+		var err error
+		validator, err = app.NewInventoryValidator(config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+				EquipmentCountTarget: "Menge",
+			},
+		}, &utilsfakes.FakeLogger{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should report no findings for a valid inventory", func() {
+		report := validator.Validate(app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge"},
+			{"1", "1111", "5678", "1"},
+			{"2", "2222", "", "2"},
+			{"3", "3333", "9012", "1"},
+			{"1", "4444", "3456", "1"},
+		})
+
+		Expect(report).To(BeEmpty())
+	})
+
+	It("should report missing columns", func() {
+		report := validator.Validate(app.CSVContent{
+			{"Ebene", "Sachnummer"},
+			{"1", "1111"},
+		})
+
+		Expect(report).To(Equal(app.ValidationReport{
+			{Category: app.ValidationMissingColumn, Line: 1, Message: "column equipment_id ('Inventar Nr') not found in header"},
+			{Category: app.ValidationMissingColumn, Line: 1, Message: "column equipment_count_target ('Menge') not found in header"},
+		}))
+	})
+
+	It("should report the problems with their line numbers", func() {
+		report := validator.Validate(app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge"},
+			{"1", "1111", "5678", "1"},
+			{"3", "2222", "", "x"},
+			{"", "3333", "5678", "1"},
+			{"2", "", "", "1"},
+			{"2", "4444"},
+		})
+
+		Expect(report).To(ConsistOf(
+			app.ValidationFinding{Category: app.ValidationLayerJump, Line: 3, Message: "layer 3 follows layer 1"},
+			app.ValidationFinding{Category: app.ValidationInvalidTarget, Line: 3, Message: "'x' is not a number"},
+			app.ValidationFinding{Category: app.ValidationDuplicateID, Line: 4, Message: "'5678' already used on line 2"},
+			app.ValidationFinding{Category: app.ValidationInvalidLayer, Line: 4, Message: "'' is not a number"},
+			app.ValidationFinding{Category: app.ValidationEmptyPartNumber, Line: 5, Message: "no inventory number and no part number"},
+			app.ValidationFinding{Category: app.ValidationShortRow, Line: 6, Message: "2 of 4 columns"},
+			app.ValidationFinding{Category: app.ValidationInvalidTarget, Line: 6, Message: "'' is not a number"},
+		))
+	})
+
+	It("should log the findings grouped by category", func() {
+		logger := &utilsfakes.FakeLogger{}
+
+		app.ValidationReport{
+			{Category: app.ValidationInvalidTarget, Line: 3, Message: "'x' is not a number"},
+			{Category: app.ValidationDuplicateID, Line: 4, Message: "'5678' already used on line 2"},
+		}.Log(logger)
+
+		Expect(logger.WarnArgsForCall(0)).To(Equal("found 2 problems:"))
+		Expect(logger.WarnArgsForCall(2)).To(Equal("duplicate inventory number (1):"))
+		Expect(logger.WarnIndentedArgsForCall(0)).To(Equal("line     4: '5678' already used on line 2"))
+		Expect(logger.WarnArgsForCall(4)).To(Equal("invalid target (1):"))
+		Expect(logger.WarnIndentedArgsForCall(1)).To(Equal("line     3: 'x' is not a number"))
+	})
+})
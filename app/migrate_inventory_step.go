@@ -0,0 +1,217 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+type MigrateInventoryStep interface {
+	Migrate() error
+}
+
+type inventoryMigrator struct {
+	config config.Config
+	logger utils.Logger
+}
+
+func NewMigrateInventoryStep(config config.Config, logger utils.Logger) MigrateInventoryStep {
+	return &inventoryMigrator{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (m *inventoryMigrator) Migrate() error {
+	if m.config.MigrationExport == "" {
+		return errors.New("no new THWin export given, set it with -e or migration_export")
+	}
+
+	exportPath := m.config.MigrationExport
+	if !filepath.IsAbs(exportPath) {
+		exportPath = filepath.Join(m.config.WorkingDir, exportPath)
+	}
+
+	previousPath, err := m.getPreviousInventoryPath()
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info(fmt.Sprintf("migrating counts of '%s' into '%s'", filepath.Base(previousPath), filepath.Base(exportPath)))
+
+	previous, err := ReadInventoryFile(previousPath, m.logger)
+	if err != nil {
+		return err
+	}
+
+	export, err := ReadInventoryFile(exportPath, m.logger)
+	if err != nil {
+		return err
+	}
+
+	mappingPath := m.config.GetAbsolutePseudoIDMappingFileName()
+
+	var mapping PseudoIDMapping
+	if mappingPath != "" {
+		mapping, err = ReadPseudoIDMapping(mappingPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	inventoryData, report, err := NewInventoryMigration(m.config, m.logger).Migrate(previous, export, mapping)
+	if err != nil {
+		return err
+	}
+
+	m.logReport(report)
+
+	filePath := m.config.GetAbsoluteInventoryCSVFileName()
+
+	err = m.backupInventoryFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	if mapping != nil {
+		err = mapping.Write(mappingPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = WriteInventoryFile(filePath, inventoryData, m.config, m.logger)
+	if err != nil {
+		return err
+	}
+
+	err = m.moveExportFile(exportPath)
+	if err != nil {
+		return err
+	}
+
+	// saved after the file, a later change of the file is detected by its modification time
+	if databasePath := m.config.GetAbsoluteDatabaseFileName(); databasePath != "" {
+		return saveInventoryToDatabase(databasePath, "migrate", inventoryData.GetContent(), m.config, m.logger)
+	}
+
+	return nil
+}
+
+// getPreviousInventoryPath returns the baseline result if given, the latest result or the initialized inventory otherwise
+func (m *inventoryMigrator) getPreviousInventoryPath() (string, error) {
+	baselineConfig := m.config
+	if baselineConfig.BaselineResult == "" {
+		baselineConfig.BaselineResult = "latest"
+	}
+
+	path, err := getBaselineResultPath(baselineConfig)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		return m.config.GetAbsoluteInventoryCSVFileName(), nil
+	}
+
+	return path, nil
+}
+
+// backupInventoryFile keeps the inventory file before it is replaced by the migrated one
+func (m *inventoryMigrator) backupInventoryFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read inventory file '%s': %w", filePath, err)
+	}
+
+	err = os.MkdirAll(m.config.GetResultDir(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create result dir: %w", err)
+	}
+
+	ext := filepath.Ext(filePath)
+	backupPath := filepath.Join(
+		m.config.GetResultDir(),
+		fmt.Sprintf("%s_before_migration_%s%s", filepath.Base(filePath[:len(filePath)-len(ext)]), time.Now().Format("2006-01-02_15-04-05"), ext),
+	)
+
+	err = os.WriteFile(backupPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write backup '%s': %w", backupPath, err)
+	}
+
+	m.logger.Info(fmt.Sprintf("saved previous inventory file to '%s'", backupPath))
+
+	return nil
+}
+
+// moveExportFile moves an export within the working dir into the result dir, otherwise it would be read
+// as a file with recorded equipment by the following steps
+func (m *inventoryMigrator) moveExportFile(exportPath string) error {
+	if filepath.Clean(filepath.Dir(exportPath)) != filepath.Clean(m.config.WorkingDir) {
+		return nil
+	}
+
+	err := os.MkdirAll(m.config.GetResultDir(), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create result dir: %w", err)
+	}
+
+	ext := filepath.Ext(exportPath)
+	movedPath := filepath.Join(
+		m.config.GetResultDir(),
+		fmt.Sprintf("%s_migrated_%s%s", filepath.Base(exportPath[:len(exportPath)-len(ext)]), time.Now().Format("2006-01-02_15-04-05"), ext),
+	)
+
+	err = os.Rename(exportPath, movedPath)
+	if err != nil {
+		return fmt.Errorf("failed to move THWin export '%s': %w", exportPath, err)
+	}
+
+	m.logger.Info(fmt.Sprintf("moved THWin export to '%s'", movedPath))
+
+	return nil
+}
+
+func (m *inventoryMigrator) logReport(report MigrationReport) {
+	m.logger.Info(fmt.Sprintf("migrated %d lines", report.Migrated))
+	m.logger.Info("")
+
+	unmatched := ReportTable{
+		Title: "counted lines of the previous inventory missing in the new export:",
+		Columns: []ReportColumn{
+			{Header: "line", AlignRight: true},
+			{Header: "equipment"},
+			{Header: "actual", AlignRight: true},
+			{Header: "description"},
+		},
+		Warning: true,
+	}
+	for _, info := range report.Unmatched {
+		unmatched.Rows = append(unmatched.Rows, []string{strconv.Itoa(info.Line), info.ID, info.Actual, info.Description})
+	}
+
+	added := ReportTable{
+		Title: "lines of the new export missing in the previous inventory:",
+		Columns: []ReportColumn{
+			{Header: "line", AlignRight: true},
+			{Header: "equipment"},
+			{Header: "description"},
+		},
+	}
+	for _, info := range report.Added {
+		added.Rows = append(added.Rows, []string{strconv.Itoa(info.Line), info.ID, info.Description})
+	}
+
+	reporter := NewReporter(m.logger)
+	reporter.Table(unmatched)
+	reporter.Table(added)
+}
@@ -0,0 +1,106 @@
+package app_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MigrateInventoryStep", func() {
+
+	var (
+		tempDir string
+		cfg     *config.Config
+		logger  *utilsfakes.FakeLogger
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "migrate-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath := filepath.Join(tempDir, "config.json")
+		Expect(os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+			"working_dir": %q,
+			"inventory_csv_file_name": "inventory.csv",
+			"columns": {
+				"equipment_layer": "Ebene",
+				"equipment_part_number": "Sachnummer",
+				"equipment_id": "Inventar Nr",
+				"equipment_count_actual": "Bestand IST",
+				"equipment_count_target": "Menge"
+			}
+		}`, tempDir)), 0644)).To(Succeed())
+
+		logger = &utilsfakes.FakeLogger{}
+		cfg, err = config.LoadConfig(configPath, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		csvFile := app.NewCSVFile(logger)
+		Expect(csvFile.Write(cfg.GetAbsoluteInventoryCSVFileName(), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "0591-S00001", "1", "1"},
+		})).To(Succeed())
+		Expect(csvFile.Write(filepath.Join(tempDir, "neuer_export.csv"), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge"},
+			{"1", "1111", "0591-S00001", "1"},
+			{"1", "2222", "0591-S00002", "1"},
+		})).To(Succeed())
+		Expect(csvFile.Write(filepath.Join(tempDir, "scan_1.csv"), app.CSVContent{
+			{"0591-S00002"},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should move an export of the working dir into the result dir", func() {
+		cfg.MigrationExport = "neuer_export.csv"
+
+		Expect(app.NewMigrateInventoryStep(*cfg, logger).Migrate()).To(Succeed())
+
+		Expect(filepath.Join(tempDir, "neuer_export.csv")).ToNot(BeAnExistingFile())
+		moved, err := filepath.Glob(filepath.Join(cfg.GetResultDir(), "neuer_export_migrated_*.csv"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(HaveLen(1))
+	})
+
+	It("should not read the export as scans when processing after the migration", func() {
+		cfg.MigrationExport = "neuer_export.csv"
+		Expect(app.NewMigrateInventoryStep(*cfg, logger).Migrate()).To(Succeed())
+
+		statistics, err := app.NewProcessInvetoryStep(*cfg, logger).Process()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(statistics.UnknownScans).To(Equal(0))
+		Expect(statistics.CountedItems).To(Equal(2))
+
+		var files []string
+		for i := 0; i < logger.InfoIndentedCallCount(); i++ {
+			files = append(files, logger.InfoIndentedArgsForCall(i))
+		}
+		Expect(files).To(ContainElement("using 'scan_1.csv'"))
+		Expect(files).ToNot(ContainElement("using 'neuer_export.csv'"))
+	})
+
+	It("should keep an export outside of the working dir", func() {
+		exportDir, err := os.MkdirTemp("", "migrate-export")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(exportDir)
+
+		exportPath := filepath.Join(exportDir, "neuer_export.csv")
+		Expect(os.Rename(filepath.Join(tempDir, "neuer_export.csv"), exportPath)).To(Succeed())
+		cfg.MigrationExport = exportPath
+
+		Expect(app.NewMigrateInventoryStep(*cfg, logger).Migrate()).To(Succeed())
+
+		Expect(exportPath).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// GetOutputEncoding returns the configured encoding of the written CSV files, UTF-8 by default.
+// The input encoding is detected from the inventory file, a JSON or missing inventory file is written as UTF-8.
+func GetOutputEncoding(cfg config.Config, logger utils.Logger) (encoding.Encoding, error) {
+	switch cfg.OutputEncoding {
+	case "":
+		return unicode.UTF8, nil
+	case config.OutputEncodingInput:
+		filePath := cfg.GetAbsoluteInventoryCSVFileName()
+		if isJSONFile(filePath) {
+			return unicode.UTF8, nil
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			return unicode.UTF8, nil
+		}
+		return NewEncodingProvider(logger).GetFileEncoding(filePath)
+	default:
+		return getEncodingByName(cfg.OutputEncoding)
+	}
+}
+
+// NewOutputCSVFile returns a CSV file written in the output encoding
+func NewOutputCSVFile(cfg config.Config, logger utils.Logger) (CSVFile, error) {
+	enc, err := GetOutputEncoding(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output encoding: %w", err)
+	}
+
+	return NewCSVFileWithEncoding(enc, logger), nil
+}
@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"thwInventoryMerge/config"
 	"thwInventoryMerge/utils"
 	"time"
 )
 
 type ProcessInvetoryStep interface {
-	Process() error
+	// Process writes the merged result and returns its statistics
+	Process() (InventoryStatistics, error)
 }
 
 type inventoryProcessor struct {
@@ -25,80 +29,374 @@ func NewProcessInvetoryStep(config config.Config, logger utils.Logger) ProcessIn
 	}
 }
 
-func (p *inventoryProcessor) Process() error {
+func (p *inventoryProcessor) Process() (InventoryStatistics, error) {
 	csvFiles, err := p.config.GetCSVFilesWithRecordedEquipment()
 	if err != nil {
-		p.logger.Fatal(fmt.Sprintf("Failed to get CSV files: %v", err))
+		return InventoryStatistics{}, fmt.Errorf("failed to get CSV files: %v", err)
 	}
 
 	csvFile := NewCSVFile(p.logger)
 
 	var recordedInventoryData []CSVContent
 	for _, file := range csvFiles {
-		encoding, err := NewEncodingProvider(p.logger).GetFileEncoding(file)
+		content, err := p.readCSVFile(csvFile, file)
 		if err != nil {
-			p.logger.Fatal(fmt.Sprintf("Failed to get encoding of file '%s': %v", file, err))
-		}
-
-		content, err := csvFile.Read(file, encoding)
-		if err != nil {
-			p.logger.Fatal(fmt.Sprintf("Failed to read CSV file '%s': %v", file, err))
+			return InventoryStatistics{}, err
 		}
 		recordedInventoryData = append(recordedInventoryData, content)
 	}
 
-	recordedInventory := NewRecordedInventory(recordedInventoryData)
-
-	filePath := p.config.GetAbsoluteInventoryCSVFileName()
+	storedScans, storedFiles, err := LoadStoredScans(p.config, csvFiles, p.logger)
+	if err != nil {
+		return InventoryStatistics{}, err
+	}
+	recordedInventoryData = append(recordedInventoryData, storedScans...)
+	csvFiles = append(csvFiles, storedFiles...)
 
-	encoding, err := NewEncodingProvider(p.logger).GetFileEncoding(filePath)
+	normalizer, err := NewScanNormalizer(p.config)
 	if err != nil {
-		p.logger.Fatal(fmt.Sprintf("Failed to get encoding of file '%s': %v", filePath, err))
+		return InventoryStatistics{}, err
 	}
 
-	content, err := csvFile.Read(filePath, encoding)
+	recordedInventory := NewRecordedInventoryFromFiles(recordedInventoryData, csvFiles, normalizer)
+
+	filePath := p.config.GetAbsoluteInventoryCSVFileName()
+
+	content, err := LoadInventory(p.config, p.logger)
 	if err != nil {
-		return fmt.Errorf("failed to read CSV file '%s': %v", p.config.GetAbsoluteInventoryCSVFileName(), err)
+		return InventoryStatistics{}, err
 	}
 
 	inventoryData, err := NewInventoryData(content, p.config, p.logger)
 	if err != nil {
-		return fmt.Errorf("failed to init inventory data: %v", err)
+		return InventoryStatistics{}, fmt.Errorf("failed to init inventory data: %v", err)
 	}
 
 	inventoryMap, err := recordedInventory.AsMap()
 	if err != nil {
-		return fmt.Errorf("failed to convert recorded inventory to map: %v", err)
+		return InventoryStatistics{}, fmt.Errorf("failed to convert recorded inventory to map: %v", err)
 	}
 
-	p.logger.Info("recorded equipment:")
-	p.logger.Info("")
-	p.logger.InfoIndented("equipment                 : amount")
-	p.logger.InfoIndented("----------------------------------")
-	for key, value := range inventoryMap {
-		p.logger.InfoIndented(fmt.Sprintf("%-25s : %5d", key, value))
+	origins, err := recordedInventory.Origins()
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to get origins of recorded inventory: %v", err)
 	}
-	p.logger.Info("")
 
-	err = inventoryData.UpdateInventory(inventoryMap)
+	recordedTable := ReportTable{
+		Title:   "recorded equipment:",
+		Columns: []ReportColumn{{Header: "equipment"}, {Header: "amount", AlignRight: true}},
+	}
+	for _, id := range SortScanIDs(inventoryMap, origins, p.config.ReportOrder) {
+		recordedTable.Rows = append(recordedTable.Rows, []string{id, strconv.Itoa(inventoryMap[id])})
+	}
+	NewReporter(p.logger).Table(recordedTable)
+
+	classifier, err := NewScanClassifier(p.config)
 	if err != nil {
-		return fmt.Errorf("failed to update inventory: %v", err)
+		return InventoryStatistics{}, err
 	}
 
-	resultDir := filepath.Join(p.config.WorkingDir, "result")
+	classes, err := recordedInventory.Classify(classifier)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to classify recorded inventory: %v", err)
+	}
+
+	malformed := p.separateMalformedScans(inventoryMap, origins, classes, inventoryData)
+
+	err = inventoryData.UpdateInventory(inventoryMap, origins)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to update inventory: %v", err)
+	}
+
+	annotations, ignoredCommands := recordedInventory.Annotations()
+	p.reportIgnoredCommands(ignoredCommands)
+	inventoryData.AnnotateInventory(annotations, origins)
+
+	result := inventoryData.GetResultContent()
+
+	baselineMerge := NewBaselineMerge(inventoryData.Columns(), p.logger)
+
+	counts, err := baselineMerge.Counts(result)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to get counts of result: %v", err)
+	}
+
+	if p.config.BaselineResult != "" {
+		result, err = p.mergeBaselineResult(baselineMerge, result, counts)
+		if err != nil {
+			return InventoryStatistics{}, fmt.Errorf("failed to merge baseline result: %v", err)
+		}
+	}
+
+	resultDir := p.config.GetResultDir()
 
 	err = os.MkdirAll(resultDir, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to create result directory: %v", err)
+		return InventoryStatistics{}, fmt.Errorf("failed to create result directory: %v", err)
+	}
+
+	csvFile, err = NewOutputCSVFile(p.config, p.logger)
+	if err != nil {
+		return InventoryStatistics{}, err
+	}
+
+	now := time.Now()
+	timestamp := now.Format("2006-01-02_15-04-05")
+
+	err = csvFile.Write(
+		filepath.Join(resultDir, fmt.Sprintf("result_%s.csv", timestamp)),
+		result,
+	)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to write result csv: %v", err)
+	}
+
+	resultData, err := NewInventoryData(result, p.config, p.logger)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to init result data: %v", err)
+	}
+
+	var scanFiles []string
+	for _, file := range csvFiles {
+		scanFiles = append(scanFiles, filepath.Base(file))
+	}
+
+	err = NewInventoryJSON(p.logger).Write(
+		filepath.Join(resultDir, fmt.Sprintf("result_%s.json", timestamp)),
+		resultData,
+		InventoryMetadata{
+			CreatedAt:     now,
+			InventoryFile: filepath.Base(filePath),
+			ScanFiles:     scanFiles,
+		},
+	)
+	if err != nil {
+		return InventoryStatistics{}, err
 	}
 
+	if databasePath := p.config.GetAbsoluteDatabaseFileName(); databasePath != "" {
+		err = p.saveToDatabase(databasePath, csvFiles, recordedInventoryData, result, now)
+		if err != nil {
+			return InventoryStatistics{}, err
+		}
+	}
+
+	// a stable file name for tools watching the result, it may be locked e.g. by Excel
+	err = csvFile.Write(filepath.Join(resultDir, "latest.csv"), result)
+	if err != nil {
+		p.logger.Warn(fmt.Sprintf("failed to write latest result csv: %v", err))
+	}
+
+	// the recorded counts are required to detect manual changes when the result is used as baseline
+	err = csvFile.Write(
+		filepath.Join(resultDir, fmt.Sprintf("counts_%s.csv", timestamp)),
+		counts,
+	)
+	if err != nil {
+		return InventoryStatistics{}, fmt.Errorf("failed to write counts csv: %v", err)
+	}
+
+	auditLog := inventoryData.GetAuditLog()
+
 	err = csvFile.Write(
-		filepath.Join(resultDir, fmt.Sprintf("result_%s.csv", time.Now().Format("2006-01-02_15-04-05"))),
-		inventoryData.GetContent(),
+		filepath.Join(resultDir, fmt.Sprintf("audit_%s.csv", timestamp)),
+		auditLog.AsCSVContent(),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to write result csv: %v", err)
+		return InventoryStatistics{}, fmt.Errorf("failed to write audit csv: %v", err)
+	}
+
+	err = auditLog.WriteJSON(filepath.Join(resultDir, fmt.Sprintf("audit_%s.json", timestamp)))
+	if err != nil {
+		return InventoryStatistics{}, err
+	}
+
+	surplus := inventoryData.GetSurplus()
+	if len(surplus) > 0 {
+		surplusContent := CSVContent{{inventoryData.Columns().EquipmentID, "Überzählig"}}
+		for _, id := range SortScanIDs(surplus, origins, p.config.ReportOrder) {
+			surplusContent = append(surplusContent, []string{id, strconv.Itoa(surplus[id])})
+		}
+
+		err = csvFile.Write(
+			filepath.Join(resultDir, fmt.Sprintf("surplus_%s.csv", timestamp)),
+			surplusContent,
+		)
+		if err != nil {
+			return InventoryStatistics{}, fmt.Errorf("failed to write surplus csv: %v", err)
+		}
+	}
+
+	statistics := NewInventoryStatistics(resultData.GetEquipment(), inventoryData.GetUnknownScans(), p.config)
+	for _, amount := range malformed {
+		statistics.MalformedScans += amount
+	}
+	statistics.Log(p.logger)
+
+	err = statistics.WriteJSON(filepath.Join(resultDir, fmt.Sprintf("statistics_%s.json", timestamp)))
+	if err != nil {
+		return InventoryStatistics{}, err
+	}
+
+	return statistics, nil
+}
+
+func (p *inventoryProcessor) saveToDatabase(databasePath string, csvFiles []string, recordedInventoryData []CSVContent, result CSVContent, now time.Time) error {
+	store, err := OpenInventoryStore(databasePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var scanFiles []string
+	for i, file := range csvFiles {
+		scanFiles = append(scanFiles, filepath.Base(file))
+
+		err = store.SaveScans(filepath.Base(file), recordedInventoryData[i])
+		if err != nil {
+			return fmt.Errorf("failed to save scans to database '%s': %w", databasePath, err)
+		}
+	}
+
+	err = store.SaveSession(InventorySession{
+		ID:            now.Format("2006-01-02_15-04-05"),
+		Step:          "process",
+		StartedAt:     now,
+		InventoryFile: p.config.InventoryCSVFileName,
+		ScanFiles:     scanFiles,
+	}, result)
+	if err != nil {
+		return fmt.Errorf("failed to save session to database '%s': %w", databasePath, err)
 	}
 
 	return nil
 }
+
+func (p *inventoryProcessor) mergeBaselineResult(baselineMerge BaselineMerge, result CSVContent, counts CSVContent) (CSVContent, error) {
+	baselinePath, err := getBaselineResultPath(p.config)
+	if err != nil {
+		return nil, err
+	}
+
+	if baselinePath == "" {
+		p.logger.Info("no previous result found, processing without baseline")
+		p.logger.Info("")
+
+		return result, nil
+	}
+
+	p.logger.Info(fmt.Sprintf("using '%s' as baseline", baselinePath))
+	p.logger.Info("")
+
+	csvFile := NewCSVFile(p.logger)
+
+	baseline, err := p.readCSVFile(csvFile, baselinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	countsPath := filepath.Join(filepath.Dir(baselinePath), "counts_"+strings.TrimPrefix(filepath.Base(baselinePath), "result_"))
+
+	var previousCounts CSVContent
+	if _, err := os.Stat(countsPath); err == nil {
+		previousCounts, err = p.readCSVFile(csvFile, countsPath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.logger.Warn(fmt.Sprintf("no counts found at '%s', all counts of the baseline are kept as manual values", countsPath))
+	}
+
+	merged, err := baselineMerge.Merge(baseline, previousCounts, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// recalculate the computed columns
+	mergedData, err := NewInventoryData(merged, p.config, p.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergedData.GetResultContent(), nil
+}
+
+// getBaselineResultPath returns an empty path if 'latest' is configured and there is no result yet
+func getBaselineResultPath(config config.Config) (string, error) {
+	if config.BaselineResult != "latest" {
+		if filepath.IsAbs(config.BaselineResult) {
+			return config.BaselineResult, nil
+		}
+		return filepath.Join(config.WorkingDir, config.BaselineResult), nil
+	}
+
+	results, err := filepath.Glob(filepath.Join(config.GetResultDir(), "result_*.csv"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(results) == 0 {
+		return "", nil
+	}
+
+	// the timestamp format sorts chronologically
+	sort.Strings(results)
+
+	return results[len(results)-1], nil
+}
+
+func (p *inventoryProcessor) readCSVFile(csvFile CSVFile, filePath string) (CSVContent, error) {
+	encoding, err := NewEncodingProvider(p.logger).GetFileEncoding(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encoding of file '%s': %v", filePath, err)
+	}
+
+	content, err := csvFile.Read(filePath, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file '%s': %v", filePath, err)
+	}
+
+	return content, nil
+}
+
+// separateMalformedScans removes the malformed scans which are not in the inventory from the recorded
+// inventory and reports them with their origins, those are misreads rather than unknown equipment
+func (p *inventoryProcessor) separateMalformedScans(inventoryMap RecordedInventoryMap, origins ScanOriginsMap, classes ScanClassMap, inventoryData InventoryData) RecordedInventoryMap {
+	malformed := make(RecordedInventoryMap)
+
+	for id, amount := range inventoryMap {
+		if classes[id].Class != ScanClassMalformed || len(inventoryData.FindEquipment(id)) > 0 {
+			continue
+		}
+		malformed[id] = amount
+		delete(inventoryMap, id)
+	}
+
+	table := ReportTable{
+		Title:   "malformed scans, the equipment has to be scanned again:",
+		Columns: []ReportColumn{{Header: "file"}, {Header: "line", AlignRight: true}, {Header: "scan"}, {Header: "format"}},
+		Warning: true,
+	}
+	for _, id := range SortScanIDs(malformed, origins, config.ReportOrderFile) {
+		for _, origin := range origins[id] {
+			table.Rows = append(table.Rows, []string{origin.File, strconv.Itoa(origin.Line), id, classes[id].Format})
+		}
+	}
+	NewReporter(p.logger).Table(table)
+
+	return malformed
+}
+
+// reportIgnoredCommands lists the command barcodes which are not applied, those are kept in the
+// scan files so the processing does not fail on every run until the files are fixed
+func (p *inventoryProcessor) reportIgnoredCommands(ignored []IgnoredScanCommand) {
+	table := ReportTable{
+		Title:   "ignored command barcodes:",
+		Columns: []ReportColumn{{Header: "file"}, {Header: "line", AlignRight: true}, {Header: "command"}, {Header: "problem"}},
+		Warning: true,
+	}
+	for _, command := range ignored {
+		table.Rows = append(table.Rows, []string{command.Origin.File, strconv.Itoa(command.Origin.Line), command.Command, command.Problem})
+	}
+	NewReporter(p.logger).Table(table)
+}
@@ -0,0 +1,82 @@
+package app_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProcessInventoryStep", func() {
+
+	var (
+		tempDir string
+		cfg     *config.Config
+		logger  *utilsfakes.FakeLogger
+	)
+
+	readResultFile := func(pattern string) app.CSVContent {
+		files, err := filepath.Glob(filepath.Join(cfg.GetResultDir(), pattern))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+
+		encoding, err := app.NewEncodingProvider(logger).GetFileEncoding(files[0])
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := app.NewCSVFile(logger).Read(files[0], encoding)
+		Expect(err).ToNot(HaveOccurred())
+		return content
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "process-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath := filepath.Join(tempDir, "config.json")
+		Expect(os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+			"working_dir": %q,
+			"inventory_csv_file_name": "inventory.csv",
+			"columns": {
+				"equipment_layer": "Ebene",
+				"equipment_part_number": "Sachnummer",
+				"equipment_id": ["Inventarnummer", "Inventar Nr"],
+				"equipment_count_actual": "Bestand IST",
+				"equipment_count_target": "Menge"
+			}
+		}`, tempDir)), 0644)).To(Succeed())
+
+		logger = &utilsfakes.FakeLogger{}
+		cfg, err = config.LoadConfig(configPath, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		csvFile := app.NewCSVFile(logger)
+		Expect(csvFile.Write(cfg.GetAbsoluteInventoryCSVFileName(), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "0591-S00001", "1", ""},
+			{"1", "2222", "0591-S00002", "1", ""},
+		})).To(Succeed())
+		Expect(csvFile.Write(filepath.Join(tempDir, "scan_1.csv"), app.CSVContent{
+			{"0591-S00001"}, {"0591-S00001"},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should write the surplus with the header of the matching ID column", func() {
+		_, err := app.NewProcessInvetoryStep(*cfg, logger).Process()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(readResultFile("surplus_*.csv")).To(Equal(app.CSVContent{
+			{"Inventar Nr", "Überzählig"},
+			{"0591-s00001", "1"},
+		}))
+	})
+})
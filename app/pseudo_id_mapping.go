@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const pseudoIDMappingVersion = 1
+
+// PseudoIDIdentity identifies a line without inventory number independent of its position in the THWin export
+type PseudoIDIdentity struct {
+	ParentID    string `json:"parent_id"`
+	PartNumber  string `json:"part_number"`
+	Description string `json:"description"`
+
+	// Ordinal counts the lines with the same parent ID, part number and description, starting with 1
+	Ordinal int `json:"ordinal"`
+}
+
+// sameItem returns true if both identities describe the same kind of equipment
+func (i PseudoIDIdentity) sameItem(other PseudoIDIdentity) bool {
+	return i.ParentID == other.ParentID && i.PartNumber == other.PartNumber && i.Description == other.Description
+}
+
+func (i PseudoIDIdentity) String() string {
+	return fmt.Sprintf("%s #%d in %s (%s)", i.Description, i.Ordinal, i.ParentID, i.PartNumber)
+}
+
+type PseudoIDMappingEntry struct {
+	ID string `json:"id"`
+	PseudoIDIdentity
+}
+
+type pseudoIDMappingDocument struct {
+	Version int                    `json:"version"`
+	Entries []PseudoIDMappingEntry `json:"entries"`
+}
+
+// PseudoIDMapping pins generated pseudo IDs to the identity of their lines
+type PseudoIDMapping interface {
+	Lookup(identity PseudoIDIdentity) (string, bool)
+
+	// Owner returns the first identity the ID is pinned to
+	Owner(id string) (PseudoIDIdentity, bool)
+
+	Pin(identity PseudoIDIdentity, id string)
+
+	// Orphaned returns the entries whose identity is not in the given list
+	Orphaned(identities []PseudoIDIdentity) []PseudoIDMappingEntry
+
+	Write(filePath string) error
+}
+
+type pseudoIDMapping struct {
+	entries []PseudoIDMappingEntry
+}
+
+func NewPseudoIDMapping() PseudoIDMapping {
+	return &pseudoIDMapping{}
+}
+
+// ReadPseudoIDMapping returns an empty mapping if the file does not exist yet
+func ReadPseudoIDMapping(filePath string) (PseudoIDMapping, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewPseudoIDMapping(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pseudo ID mapping '%s': %w", filePath, err)
+	}
+
+	var document pseudoIDMappingDocument
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pseudo ID mapping '%s': %w", filePath, err)
+	}
+
+	if document.Version != pseudoIDMappingVersion {
+		return nil, fmt.Errorf("unsupported version %d of pseudo ID mapping '%s'", document.Version, filePath)
+	}
+
+	return &pseudoIDMapping{
+		entries: document.Entries,
+	}, nil
+}
+
+func (m *pseudoIDMapping) Lookup(identity PseudoIDIdentity) (string, bool) {
+	for _, entry := range m.entries {
+		if entry.PseudoIDIdentity == identity {
+			return entry.ID, true
+		}
+	}
+	return "", false
+}
+
+func (m *pseudoIDMapping) Owner(id string) (PseudoIDIdentity, bool) {
+	for _, entry := range m.entries {
+		if entry.ID == id {
+			return entry.PseudoIDIdentity, true
+		}
+	}
+	return PseudoIDIdentity{}, false
+}
+
+func (m *pseudoIDMapping) Pin(identity PseudoIDIdentity, id string) {
+	for i, entry := range m.entries {
+		if entry.PseudoIDIdentity == identity {
+			m.entries[i].ID = id
+			return
+		}
+	}
+	m.entries = append(m.entries, PseudoIDMappingEntry{ID: id, PseudoIDIdentity: identity})
+}
+
+func (m *pseudoIDMapping) Orphaned(identities []PseudoIDIdentity) []PseudoIDMappingEntry {
+	known := make(map[PseudoIDIdentity]bool)
+	for _, identity := range identities {
+		known[identity] = true
+	}
+
+	var result []PseudoIDMappingEntry
+	for _, entry := range m.entries {
+		if !known[entry.PseudoIDIdentity] {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func (m *pseudoIDMapping) Write(filePath string) error {
+	entries := append([]PseudoIDMappingEntry{}, m.entries...)
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].ID < entries[b].ID
+	})
+
+	data, err := json.MarshalIndent(pseudoIDMappingDocument{
+		Version: pseudoIDMappingVersion,
+		Entries: entries,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pseudo ID mapping: %w", err)
+	}
+
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write pseudo ID mapping '%s': %w", filePath, err)
+	}
+
+	return nil
+}
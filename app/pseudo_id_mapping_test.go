@@ -0,0 +1,82 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PseudoIDMapping", func() {
+
+	var (
+		tempDir   string
+		spanngurt app.PseudoIDIdentity
+		hammer    app.PseudoIDIdentity
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "pseudo-id-mapping")
+		Expect(err).ToNot(HaveOccurred())
+
+		spanngurt = app.PseudoIDIdentity{ParentID: "5678", PartNumber: "2222", Description: "Spanngurt", Ordinal: 1}
+		hammer = app.PseudoIDIdentity{ParentID: "5678", PartNumber: "3333", Description: "Hammer", Ordinal: 1}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should return an empty mapping if the file does not exist", func() {
+		mapping, err := app.ReadPseudoIDMapping(filepath.Join(tempDir, "missing.json"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, ok := mapping.Lookup(spanngurt)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should write and read the pinned IDs", func() {
+		filePath := filepath.Join(tempDir, "pseudo_ids.json")
+
+		mapping := app.NewPseudoIDMapping()
+		mapping.Pin(spanngurt, "5678__2222")
+		mapping.Pin(hammer, "5678__3333")
+		Expect(mapping.Write(filePath)).To(Succeed())
+
+		mapping, err := app.ReadPseudoIDMapping(filePath)
+		Expect(err).ToNot(HaveOccurred())
+
+		id, ok := mapping.Lookup(spanngurt)
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal("5678__2222"))
+
+		owner, ok := mapping.Owner("5678__3333")
+		Expect(ok).To(BeTrue())
+		Expect(owner).To(Equal(hammer))
+	})
+
+	It("should update the ID of a pinned identity", func() {
+		mapping := app.NewPseudoIDMapping()
+		mapping.Pin(spanngurt, "5678__2222")
+		mapping.Pin(spanngurt, "5678__2222__1")
+
+		id, ok := mapping.Lookup(spanngurt)
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal("5678__2222__1"))
+		_, ok = mapping.Owner("5678__2222")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should return the orphaned entries", func() {
+		mapping := app.NewPseudoIDMapping()
+		mapping.Pin(spanngurt, "5678__2222")
+		mapping.Pin(hammer, "5678__3333")
+
+		Expect(mapping.Orphaned([]app.PseudoIDIdentity{hammer})).To(Equal([]app.PseudoIDMappingEntry{
+			{ID: "5678__2222", PseudoIDIdentity: spanngurt},
+		}))
+	})
+})
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"unicode"
+)
+
+const (
+	defaultPseudoIDSeparator = "__"
+	defaultPseudoIDTemplate  = "{{.Prefix}}{{.ParentID}}{{.Separator}}{{.PartNumber}}{{if .Ordinal}}{{.Separator}}{{.Ordinal}}{{end}}"
+
+	// placeholders rendered into the template to derive the pattern of the generated IDs
+	pseudoIDParentMarker     = "\x00parent\x00"
+	pseudoIDPartNumberMarker = "\x00part\x00"
+	pseudoIDOrdinalMarker    = 918273645
+)
+
+// PseudoIDFields holds the values available in the pseudo ID template
+type PseudoIDFields struct {
+	Prefix     string
+	ParentID   string
+	Separator  string
+	PartNumber string
+
+	// Ordinal is 0 unless the ID is shared by several lines and ordinal suffixes are enabled
+	Ordinal int
+}
+
+type PseudoIDScheme interface {
+	Generate(parentID string, partNumber string, ordinal int) (string, error)
+
+	// IsPseudoID returns true for IDs created by Generate
+	IsPseudoID(id string) bool
+
+	// IsRealID returns true for inventory numbers from THWin
+	IsRealID(id string) bool
+
+	OrdinalSuffix() bool
+}
+
+type pseudoIDScheme struct {
+	template      *template.Template
+	pattern       *regexp.Regexp
+	separator     string
+	prefix        string
+	normalization string
+	ordinalSuffix bool
+}
+
+func NewPseudoIDScheme(config config.Config) (PseudoIDScheme, error) {
+	settings := config.PseudoID
+
+	value := settings.Template
+	if value == "" {
+		value = defaultPseudoIDTemplate
+	}
+
+	tmpl, err := template.New("pseudo_id").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pseudo ID template: %w", err)
+	}
+
+	separator := settings.Separator
+	if separator == "" {
+		separator = defaultPseudoIDSeparator
+	}
+
+	scheme := &pseudoIDScheme{
+		template:      tmpl,
+		separator:     separator,
+		prefix:        settings.Prefix,
+		normalization: settings.PartNumberNormalization,
+		ordinalSuffix: settings.OrdinalSuffix,
+	}
+
+	scheme.pattern, err = scheme.compilePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	return scheme, nil
+}
+
+// compilePattern renders the template with placeholders for the parent ID, the part number and the
+// ordinal, so IDs are only recognised as pseudo IDs if the template could have generated them
+func (s *pseudoIDScheme) compilePattern() (*regexp.Regexp, error) {
+	var alternatives []string
+
+	for _, ordinal := range []int{0, pseudoIDOrdinalMarker} {
+		var rendered strings.Builder
+
+		err := s.template.Execute(&rendered, PseudoIDFields{
+			Prefix:     s.prefix,
+			ParentID:   pseudoIDParentMarker,
+			Separator:  s.separator,
+			PartNumber: pseudoIDPartNumberMarker,
+			Ordinal:    ordinal,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute pseudo ID template: %w", err)
+		}
+
+		alternative := regexp.QuoteMeta(rendered.String())
+		alternative = strings.ReplaceAll(alternative, pseudoIDParentMarker, ".*")
+		alternative = strings.ReplaceAll(alternative, pseudoIDPartNumberMarker, ".*")
+		alternative = strings.ReplaceAll(alternative, strconv.Itoa(pseudoIDOrdinalMarker), "\\d+")
+		alternatives = append(alternatives, alternative)
+	}
+
+	return regexp.Compile("^(?:" + strings.Join(alternatives, "|") + ")$")
+}
+
+func (s *pseudoIDScheme) Generate(parentID string, partNumber string, ordinal int) (string, error) {
+	var id strings.Builder
+
+	err := s.template.Execute(&id, PseudoIDFields{
+		Prefix:     s.prefix,
+		ParentID:   parentID,
+		Separator:  s.separator,
+		PartNumber: s.normalizePartNumber(partNumber),
+		Ordinal:    ordinal,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute pseudo ID template: %w", err)
+	}
+
+	return id.String(), nil
+}
+
+func (s *pseudoIDScheme) normalizePartNumber(partNumber string) string {
+	partNumber = strings.TrimSpace(partNumber)
+
+	switch s.normalization {
+	case config.PartNumberNormalizationUpper:
+		return strings.ToUpper(partNumber)
+	case config.PartNumberNormalizationAlphanumeric:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToUpper(r)
+			}
+			return -1
+		}, partNumber)
+	default:
+		return partNumber
+	}
+}
+
+func (s *pseudoIDScheme) IsPseudoID(id string) bool {
+	return s.pattern.MatchString(id)
+}
+
+func (s *pseudoIDScheme) IsRealID(id string) bool {
+	return utils.StartsWithNumber(id) && !s.IsPseudoID(id)
+}
+
+func (s *pseudoIDScheme) OrdinalSuffix() bool {
+	return s.ordinalSuffix
+}
@@ -0,0 +1,83 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PseudoIDScheme", func() {
+
+	It("should generate IDs of the parent ID and the part number by default", func() {
+		scheme, err := app.NewPseudoIDScheme(config.Config{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(scheme.Generate("5678", "2222", 0)).To(Equal("5678__2222"))
+		Expect(scheme.Generate("5678", "2222", 2)).To(Equal("5678__2222__2"))
+
+		Expect(scheme.IsPseudoID("5678__2222")).To(BeTrue())
+		Expect(scheme.IsPseudoID("5678")).To(BeFalse())
+		Expect(scheme.IsRealID("5678")).To(BeTrue())
+		Expect(scheme.IsRealID("5678__2222")).To(BeFalse())
+		Expect(scheme.IsRealID("---")).To(BeFalse())
+	})
+
+	It("should use the configured separator, prefix and part number normalization", func() {
+		scheme, err := app.NewPseudoIDScheme(config.Config{
+			PseudoID: config.ConfigPseudoID{
+				Separator:               "/",
+				Prefix:                  "P-",
+				PartNumberNormalization: config.PartNumberNormalizationAlphanumeric,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(scheme.Generate("5678", " 2222-ab.1 ", 0)).To(Equal("P-5678/2222AB1"))
+
+		Expect(scheme.IsPseudoID("P-5678/2222AB1")).To(BeTrue())
+		Expect(scheme.IsPseudoID("5678/2222AB1")).To(BeFalse())
+		Expect(scheme.IsRealID("5678")).To(BeTrue())
+	})
+
+	It("should use the configured template", func() {
+		scheme, err := app.NewPseudoIDScheme(config.Config{
+			PseudoID: config.ConfigPseudoID{
+				Template:                "{{.PartNumber}}{{.Separator}}{{.ParentID}}{{.Prefix}}{{if .Ordinal}}-{{.Ordinal}}{{end}}",
+				Prefix:                  "#P",
+				PartNumberNormalization: config.PartNumberNormalizationUpper,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(scheme.Generate("5678", "ab", 3)).To(Equal("AB__5678#P-3"))
+
+		Expect(scheme.IsPseudoID("AB__5678#P-3")).To(BeTrue())
+		Expect(scheme.IsPseudoID("12__5678#P")).To(BeTrue())
+		Expect(scheme.IsRealID("12__5678#P")).To(BeFalse())
+		Expect(scheme.IsPseudoID("#P5678__AB")).To(BeFalse())
+		Expect(scheme.IsPseudoID("12__5678#P-x")).To(BeFalse())
+	})
+
+	It("should not recognise inventory numbers as pseudo IDs if the separator only occurs elsewhere", func() {
+		scheme, err := app.NewPseudoIDScheme(config.Config{
+			PseudoID: config.ConfigPseudoID{
+				Template: "{{.ParentID}}{{.Prefix}}{{.PartNumber}}{{if .Ordinal}}{{.Separator}}{{.Ordinal}}{{end}}",
+				Prefix:   "/",
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(scheme.Generate("0591-S00001", "2222", 0)).To(Equal("0591-S00001/2222"))
+		Expect(scheme.IsPseudoID("0591-S00001/2222")).To(BeTrue())
+		Expect(scheme.IsRealID("0591-S00001__2")).To(BeTrue())
+	})
+
+	It("should return an error for an invalid template", func() {
+		_, err := app.NewPseudoIDScheme(config.Config{
+			PseudoID: config.ConfigPseudoID{Template: "{{.ParentID"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
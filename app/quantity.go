@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a number of pieces or a measured amount, e.g. meters of a rope
+type Quantity float64
+
+// String formats the quantity in German number format without thousands separator
+func (q Quantity) String() string {
+	return strings.Replace(strconv.FormatFloat(float64(q), 'f', -1, 64), ".", ",", 1)
+}
+
+func (q Quantity) IsWhole() bool {
+	return float64(q) == math.Trunc(float64(q))
+}
+
+var (
+	quantityPattern        = regexp.MustCompile(`^([+-]?[0-9.,]*[0-9])\s*([\p{L}.]*)$`)
+	germanThousandsPattern = regexp.MustCompile(`^[+-]?[0-9]{1,3}(\.[0-9]{3})+$`)
+	pieceUnits             = []string{"", "stk", "stk.", "st", "st.", "stück"}
+)
+
+// ParseQuantity parses numbers like "2", "2,5", "1.000" or "1.000,5" with an optional unit suffix like "2,5 m"
+func ParseQuantity(value string) (Quantity, string, error) {
+	value = strings.TrimSpace(value)
+
+	match := quantityPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, "", fmt.Errorf("'%s' is not a number", value)
+	}
+
+	number, unit := match[1], match[2]
+
+	switch {
+	case strings.Contains(number, ","):
+		// German format, the dot separates thousands
+		number = strings.ReplaceAll(number, ".", "")
+		number = strings.Replace(number, ",", ".", 1)
+	case germanThousandsPattern.MatchString(number):
+		number = strings.ReplaceAll(number, ".", "")
+	}
+
+	result, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("'%s' is not a number", value)
+	}
+
+	return Quantity(result), unit, nil
+}
+
+// IsPieceUnit returns true if the unit counts pieces, so each scan counts one piece
+func IsPieceUnit(unit string) bool {
+	for _, pieceUnit := range pieceUnits {
+		if strings.EqualFold(strings.TrimSpace(unit), pieceUnit) {
+			return true
+		}
+	}
+	return false
+}
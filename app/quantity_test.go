@@ -0,0 +1,54 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Quantity", func() {
+
+	DescribeTable("ParseQuantity",
+		func(value string, expected app.Quantity, expectedUnit string) {
+			quantity, unit, err := app.ParseQuantity(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(quantity).To(Equal(expected))
+			Expect(unit).To(Equal(expectedUnit))
+		},
+		Entry("whole number", "2", app.Quantity(2), ""),
+		Entry("decimal comma", "2,5", app.Quantity(2.5), ""),
+		Entry("thousands separator", "1.000", app.Quantity(1000), ""),
+		Entry("thousands separator and decimal comma", "1.000,5", app.Quantity(1000.5), ""),
+		Entry("decimal point", "2.5", app.Quantity(2.5), ""),
+		Entry("surrounding spaces", " 3 ", app.Quantity(3), ""),
+		Entry("unit", "2,5 m", app.Quantity(2.5), "m"),
+		Entry("unit without space", "10kg", app.Quantity(10), "kg"),
+		Entry("unit with dot", "4 Stk.", app.Quantity(4), "Stk."),
+	)
+
+	DescribeTable("ParseQuantity errors",
+		func(value string) {
+			_, _, err := app.ParseQuantity(value)
+			Expect(err).To(MatchError("'" + value + "' is not a number"))
+		},
+		Entry("empty", ""),
+		Entry("text", "viele"),
+		Entry("several commas", "1,2,3"),
+	)
+
+	It("should format in German number format", func() {
+		Expect(app.Quantity(2).String()).To(Equal("2"))
+		Expect(app.Quantity(2.5).String()).To(Equal("2,5"))
+		Expect(app.Quantity(-0.5).String()).To(Equal("-0,5"))
+		Expect(app.Quantity(1000).String()).To(Equal("1000"))
+	})
+
+	It("should detect units counting pieces", func() {
+		Expect(app.IsPieceUnit("")).To(BeTrue())
+		Expect(app.IsPieceUnit("Stk")).To(BeTrue())
+		Expect(app.IsPieceUnit("Stück")).To(BeTrue())
+		Expect(app.IsPieceUnit("m")).To(BeFalse())
+		Expect(app.IsPieceUnit("kg")).To(BeFalse())
+	})
+})
@@ -1,22 +1,51 @@
 package app
 
 import (
-	"strings"
+	"path/filepath"
 )
 
 type RecordedInventoryMap map[string]int
 
+// ScanOrigin is the location of a scan in the files with recorded equipment
+type ScanOrigin struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+type ScanOriginsMap map[string][]ScanOrigin
+
 type RecordedInventory interface {
 	AsMap() (RecordedInventoryMap, error)
+
+	Origins() (ScanOriginsMap, error)
+
+	// Classify returns the class of each normalized scan
+	Classify(classifier ScanClassifier) (ScanClassMap, error)
+
+	// Annotations returns the conditions and notes of the command barcodes, those apply to the next scan of
+	// the file. Unknown commands and commands without a following scan are returned as ignored.
+	Annotations() (ScanAnnotationMap, []IgnoredScanCommand)
 }
 
 type recordedInventory struct {
-	data []CSVContent
+	data       []CSVContent
+	fileNames  []string
+	normalizer ScanNormalizer
 }
 
+// NewRecordedInventory creates the recorded inventory with lowercased scans
 func NewRecordedInventory(data []CSVContent) RecordedInventory {
+	return NewRecordedInventoryFromFiles(data, nil, DefaultScanNormalizer())
+}
+
+// NewRecordedInventoryFromFiles creates the recorded inventory with the names of the files
+// the data was read from, those are used for the origins of the scans. The scans are the keys
+// of the maps after normalization in lower case, see ScanNormalizer.Key.
+func NewRecordedInventoryFromFiles(data []CSVContent, fileNames []string, normalizer ScanNormalizer) RecordedInventory {
 	return recordedInventory{
-		data: data,
+		data:       data,
+		fileNames:  fileNames,
+		normalizer: normalizer,
 	}
 }
 
@@ -26,11 +55,97 @@ func (r recordedInventory) AsMap() (RecordedInventoryMap, error) {
 	for _, csvContent := range r.data {
 
 		for _, record := range csvContent {
-			if len(record) > 0 {
-				inventoryNumbers[strings.ToLower(record[0])]++
+			if len(record) > 0 && !r.isCommand(record[0]) {
+				inventoryNumbers[r.normalizer.Key(record[0])]++
 			}
 		}
 	}
 
 	return inventoryNumbers, nil
-}
\ No newline at end of file
+}
+
+func (r recordedInventory) Origins() (ScanOriginsMap, error) {
+	origins := make(ScanOriginsMap)
+
+	for i, csvContent := range r.data {
+		fileName := r.fileName(i)
+
+		for j, record := range csvContent {
+			if len(record) > 0 && !r.isCommand(record[0]) {
+				key := r.normalizer.Key(record[0])
+				origins[key] = append(origins[key], ScanOrigin{File: fileName, Line: j + 1})
+			}
+		}
+	}
+
+	return origins, nil
+}
+
+func (r recordedInventory) Classify(classifier ScanClassifier) (ScanClassMap, error) {
+	inventoryMap, err := r.AsMap()
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(ScanClassMap)
+	for scan := range inventoryMap {
+		classes[scan] = classifier.Classify(scan)
+	}
+
+	return classes, nil
+}
+
+func (r recordedInventory) Annotations() (ScanAnnotationMap, []IgnoredScanCommand) {
+	annotations := make(ScanAnnotationMap)
+	var ignored []IgnoredScanCommand
+
+	for i, csvContent := range r.data {
+		fileName := r.fileName(i)
+
+		var pending []ScanAnnotation
+		var pendingCommands []IgnoredScanCommand
+
+		for j, record := range csvContent {
+			if len(record) == 0 {
+				continue
+			}
+
+			origin := ScanOrigin{File: fileName, Line: j + 1}
+
+			if command, ok := scanCommand(record[0], r.normalizer); ok {
+				annotation, err := parseScanCommand(command)
+				if err != nil {
+					ignored = append(ignored, IgnoredScanCommand{Command: command, Problem: err.Error(), Origin: origin})
+					continue
+				}
+				pending = append(pending, annotation)
+				pendingCommands = append(pendingCommands, IgnoredScanCommand{Command: command, Problem: "not followed by a scan", Origin: origin})
+				continue
+			}
+
+			key := r.normalizer.Key(record[0])
+			for _, annotation := range pending {
+				annotation.Origin = origin
+				annotations[key] = append(annotations[key], annotation)
+			}
+			pending = nil
+			pendingCommands = nil
+		}
+
+		ignored = append(ignored, pendingCommands...)
+	}
+
+	return annotations, ignored
+}
+
+func (r recordedInventory) isCommand(scan string) bool {
+	_, ok := scanCommand(scan, r.normalizer)
+	return ok
+}
+
+func (r recordedInventory) fileName(index int) string {
+	if index < len(r.fileNames) {
+		return filepath.Base(r.fileNames[index])
+	}
+	return ""
+}
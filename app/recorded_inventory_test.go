@@ -7,6 +7,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
 )
 
 var _ = Describe("RecordedInventory", func() {
@@ -54,4 +55,102 @@ var _ = Describe("RecordedInventory", func() {
 			Expect(inventoryMap).To(HaveKeyWithValue("0591-s002319", 2))
 		})
 	})
+
+	var _ = Describe("Origins", func() {
+		It("returns the file and line of each recorded equipment", func() {
+			recordedInventory := app.NewRecordedInventoryFromFiles(
+				[]app.CSVContent{[][]string{
+					{"0509-002494"},
+					{"0591-S002360"},
+					{"0509-002494"},
+				}, [][]string{
+					{"0509-002494"},
+				}},
+				[]string{"/tmp/scanner1.csv", "/tmp/scanner2.csv"},
+				app.DefaultScanNormalizer())
+			origins, err := recordedInventory.Origins()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(origins).To(HaveLen(2))
+			Expect(origins).To(HaveKeyWithValue("0509-002494", []app.ScanOrigin{
+				{File: "scanner1.csv", Line: 1},
+				{File: "scanner1.csv", Line: 3},
+				{File: "scanner2.csv", Line: 1},
+			}))
+			Expect(origins).To(HaveKeyWithValue("0591-s002360", []app.ScanOrigin{
+				{File: "scanner1.csv", Line: 2},
+			}))
+		})
+	})
+
+	var _ = Describe("Annotations", func() {
+		It("attaches the command barcodes to the next scan of the file", func() {
+			recordedInventory := app.NewRecordedInventoryFromFiles(
+				[]app.CSVContent{[][]string{
+					{"cmd:defekt"},
+					{"CMD:NOTE: Kabel beschädigt"},
+					{"0591-S002360"},
+					{"0509-002494"},
+				}, [][]string{
+					{"CMD:REPARATUR"},
+					{"0591-S002360"},
+				}},
+				[]string{"/tmp/scanner1.csv", "/tmp/scanner2.csv"},
+				app.DefaultScanNormalizer())
+
+			annotations, ignored := recordedInventory.Annotations()
+			Expect(ignored).To(BeEmpty())
+			Expect(annotations).To(Equal(app.ScanAnnotationMap{
+				"0591-s002360": {
+					{Condition: "defekt", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 3}},
+					{Note: "Kabel beschädigt", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 3}},
+					{Condition: "reparaturbedürftig", Origin: app.ScanOrigin{File: "scanner2.csv", Line: 2}},
+				},
+			}))
+			Expect(annotations.Conditions("0591-s002360")).To(Equal("defekt, reparaturbedürftig"))
+			Expect(annotations.Notes("0591-s002360")).To(Equal("Kabel beschädigt"))
+
+			inventoryMap, err := recordedInventory.AsMap()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inventoryMap).To(Equal(app.RecordedInventoryMap{"0591-s002360": 2, "0509-002494": 1}))
+		})
+
+		It("ignores unknown commands and commands without scan", func() {
+			annotations, ignored := app.NewRecordedInventoryFromFiles(
+				[]app.CSVContent{{{"0591-S002360"}, {"CMD:KAPUTT"}, {"0509-002494"}, {"CMD:DEFEKT"}}},
+				[]string{"scanner1.csv"}, app.DefaultScanNormalizer(),
+			).Annotations()
+			Expect(annotations).To(BeEmpty())
+			Expect(ignored).To(Equal([]app.IgnoredScanCommand{
+				{Command: "CMD:KAPUTT", Problem: "unknown command 'CMD:KAPUTT'", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 2}},
+				{Command: "CMD:DEFEKT", Problem: "not followed by a scan", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 4}},
+			}))
+		})
+
+		It("detects commands after normalization and keeps the case of notes", func() {
+			normalizer, err := app.NewScanNormalizer(config.Config{
+				ScanNormalization: []config.ConfigNormalizationRule{
+					{Type: config.NormalizationStripPrefix, Value: "]C1"},
+					{Type: config.NormalizationUpper},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			recordedInventory := app.NewRecordedInventoryFromFiles(
+				[]app.CSVContent{{{"]C1CMD:DEFEKT"}, {"]C1cmd:note:Kabel 5 fehlt"}, {"]C10591-S002360"}}},
+				[]string{"scanner1.csv"}, normalizer)
+
+			annotations, ignored := recordedInventory.Annotations()
+			Expect(ignored).To(BeEmpty())
+			Expect(annotations).To(Equal(app.ScanAnnotationMap{
+				"0591-s002360": {
+					{Condition: "defekt", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 3}},
+					{Note: "Kabel 5 fehlt", Origin: app.ScanOrigin{File: "scanner1.csv", Line: 3}},
+				},
+			}))
+
+			inventoryMap, err := recordedInventory.AsMap()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inventoryMap).To(Equal(app.RecordedInventoryMap{"0591-s002360": 1}))
+		})
+	})
 })
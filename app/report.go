@@ -0,0 +1,135 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"unicode/utf8"
+)
+
+// ReportColumn is a column of a report table, the width grows with the header and the values
+type ReportColumn struct {
+	Header     string
+	AlignRight bool
+}
+
+// ReportTable is a table written to the console, the rows are written in the given order
+type ReportTable struct {
+	Title   string
+	Columns []ReportColumn
+	Rows    [][]string
+
+	// Warning writes the table as warning
+	Warning bool
+}
+
+type Reporter interface {
+	// Table writes the table, a table without rows is not written
+	Table(table ReportTable)
+}
+
+type reporter struct {
+	logger utils.Logger
+}
+
+func NewReporter(logger utils.Logger) Reporter {
+	return &reporter{
+		logger: logger,
+	}
+}
+
+func (r *reporter) Table(table ReportTable) {
+	if len(table.Rows) == 0 {
+		return
+	}
+
+	log, logIndented := r.logger.Info, r.logger.InfoIndented
+	if table.Warning {
+		log, logIndented = r.logger.Warn, r.logger.WarnIndented
+	}
+
+	widths := make([]int, len(table.Columns))
+	hasHeader := false
+	for i, column := range table.Columns {
+		widths[i] = utf8.RuneCountInString(column.Header)
+		hasHeader = hasHeader || column.Header != ""
+	}
+	for _, row := range table.Rows {
+		for i := range widths {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell(row, i)))
+		}
+	}
+
+	log(table.Title)
+	log("")
+
+	if hasHeader {
+		var headers []string
+		for _, column := range table.Columns {
+			headers = append(headers, column.Header)
+		}
+		length := 3 * (len(widths) - 1)
+		for _, width := range widths {
+			length += width
+		}
+		logIndented(r.formatRow(table.Columns, widths, headers))
+		logIndented(strings.Repeat("-", length))
+	}
+
+	for _, row := range table.Rows {
+		logIndented(r.formatRow(table.Columns, widths, row))
+	}
+
+	log("")
+}
+
+func (r *reporter) formatRow(columns []ReportColumn, widths []int, row []string) string {
+	cells := make([]string, len(columns))
+	for i, column := range columns {
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell(row, i)))
+		switch {
+		case column.AlignRight:
+			cells[i] = padding + cell(row, i)
+		case i == len(columns)-1:
+			// no trailing spaces
+			cells[i] = cell(row, i)
+		default:
+			cells[i] = cell(row, i) + padding
+		}
+	}
+	return strings.Join(cells, " : ")
+}
+
+// SortScanIDs returns the IDs of the recorded amounts in the configured report order,
+// file order is the order of the first scan and amount is the highest amount first
+func SortScanIDs(amounts map[string]int, origins ScanOriginsMap, order string) []string {
+	ids := make([]string, 0, len(amounts))
+	for id := range amounts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		switch order {
+		case config.ReportOrderFile:
+			a, b := firstOrigin(origins, ids[i]), firstOrigin(origins, ids[j])
+			if a != b {
+				return a.File < b.File || a.File == b.File && a.Line < b.Line
+			}
+		case config.ReportOrderAmount:
+			if amounts[ids[i]] != amounts[ids[j]] {
+				return amounts[ids[i]] > amounts[ids[j]]
+			}
+		}
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+func firstOrigin(origins ScanOriginsMap, id string) ScanOrigin {
+	if len(origins[id]) == 0 {
+		return ScanOrigin{}
+	}
+	return origins[id][0]
+}
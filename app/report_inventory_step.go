@@ -0,0 +1,99 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+type ReportInventoryStep interface {
+	Report() error
+}
+
+type inventoryReporter struct {
+	config config.Config
+	logger utils.Logger
+}
+
+func NewReportInventoryStep(config config.Config, logger utils.Logger) ReportInventoryStep {
+	return &inventoryReporter{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *inventoryReporter) Report() error {
+	latestConfig := r.config
+	latestConfig.BaselineResult = "latest"
+
+	filePath, err := getBaselineResultPath(latestConfig)
+	if err != nil {
+		return err
+	}
+	if filePath == "" {
+		return fmt.Errorf("no result found in '%s', the inventory has to be processed first", r.config.GetResultDir())
+	}
+
+	r.logger.Info(fmt.Sprintf("reporting '%s'", filePath))
+	r.logger.Info("")
+
+	encoding, err := NewEncodingProvider(r.logger).GetFileEncoding(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get encoding of file '%s': %w", filePath, err)
+	}
+
+	content, err := NewCSVFile(r.logger).Read(filePath, encoding)
+	if err != nil {
+		return fmt.Errorf("failed to read CSV file '%s': %v", filePath, err)
+	}
+
+	inventoryData, err := NewInventoryData(content, r.config, r.logger)
+	if err != nil {
+		return fmt.Errorf("failed to init inventory data: %v", err)
+	}
+
+	// the counts are taken from the result, which may have been changed manually since
+	statistics := NewInventoryStatistics(inventoryData.GetEquipment(), nil, r.config)
+
+	processed, err := r.readProcessedStatistics(filePath)
+	if err != nil {
+		return err
+	}
+	statistics.UnknownScans = processed.UnknownScans
+	statistics.MalformedScans = processed.MalformedScans
+
+	statistics.Log(r.logger)
+
+	return nil
+}
+
+// readProcessedStatistics returns the statistics written with the result, those hold the scans which
+// are not part of the result
+func (r *inventoryReporter) readProcessedStatistics(resultPath string) (InventoryStatistics, error) {
+	var statistics InventoryStatistics
+
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(resultPath), "result_"), ".csv")
+	filePath := filepath.Join(filepath.Dir(resultPath), fmt.Sprintf("statistics_%s.json", timestamp))
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		r.logger.Warn(fmt.Sprintf("no statistics found at '%s', unknown and malformed scans are not reported", filePath))
+		r.logger.Warn("")
+		return statistics, nil
+	}
+	if err != nil {
+		return statistics, fmt.Errorf("failed to read inventory statistics '%s': %w", filePath, err)
+	}
+
+	err = json.Unmarshal(data, &statistics)
+	if err != nil {
+		return statistics, fmt.Errorf("failed to unmarshal inventory statistics '%s': %w", filePath, err)
+	}
+
+	return statistics, nil
+}
@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReportInventoryStep", func() {
+
+	var (
+		tempDir string
+		cfg     config.Config
+		logger  *utilsfakes.FakeLogger
+	)
+
+	infoLines := func() []string {
+		var lines []string
+		for i := 0; i < logger.InfoIndentedCallCount(); i++ {
+			lines = append(lines, logger.InfoIndentedArgsForCall(i))
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "report-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg = config.Config{
+			WorkingDir:           tempDir,
+			InventoryCSVFileName: "inventory.csv",
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+				EquipmentCountTarget: "Menge",
+			},
+		}
+		logger = &utilsfakes.FakeLogger{}
+
+		Expect(os.MkdirAll(cfg.GetResultDir(), 0755)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should print the statistics of the latest result", func() {
+		csvFile := app.NewCSVFile(logger)
+		Expect(csvFile.Write(filepath.Join(cfg.GetResultDir(), "result_2024-01-01_10-00-00.csv"), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "5678", "1", "0"},
+		})).To(Succeed())
+		Expect(csvFile.Write(filepath.Join(cfg.GetResultDir(), "result_2024-01-02_10-00-00.csv"), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "5678", "1", "1"},
+			{"2", "2222", "", "4", "2"},
+		})).To(Succeed())
+		Expect(os.WriteFile(
+			filepath.Join(cfg.GetResultDir(), "statistics_2024-01-02_10-00-00.json"),
+			[]byte(`{"rows": 7, "unknown_scans": 3, "malformed_scans": 1}`), 0644,
+		)).To(Succeed())
+
+		Expect(app.NewReportInventoryStep(cfg, logger).Report()).To(Succeed())
+
+		Expect(logger.InfoArgsForCall(0)).To(ContainSubstring("result_2024-01-02_10-00-00.csv"))
+		Expect(infoLines()).To(ContainElements(
+			"rows                          :     2",
+			"items counted                 :     3 /     5 ( 60.0 %)",
+			"unknown scans                 :     3",
+			"malformed scans               :     1",
+		))
+	})
+
+	It("should warn if the statistics of the result are missing", func() {
+		Expect(app.NewCSVFile(logger).Write(filepath.Join(cfg.GetResultDir(), "result_2024-01-02_10-00-00.csv"), app.CSVContent{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "5678", "1", "1"},
+		})).To(Succeed())
+
+		Expect(app.NewReportInventoryStep(cfg, logger).Report()).To(Succeed())
+
+		Expect(logger.WarnArgsForCall(0)).To(ContainSubstring("no statistics found at"))
+		Expect(infoLines()).To(ContainElement("unknown scans                 :     0"))
+	})
+
+	It("should return an error without result", func() {
+		err := app.NewReportInventoryStep(cfg, logger).Report()
+		Expect(err).To(MatchError(ContainSubstring("the inventory has to be processed first")))
+	})
+})
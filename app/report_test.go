@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reporter", func() {
+
+	var logger *utilsfakes.FakeLogger
+
+	BeforeEach(func() {
+		logger = &utilsfakes.FakeLogger{}
+	})
+
+	It("should align the columns to the widest value", func() {
+		app.NewReporter(logger).Table(app.ReportTable{
+			Title:   "recorded equipment:",
+			Columns: []app.ReportColumn{{Header: "equipment"}, {Header: "amount", AlignRight: true}, {Header: "note"}},
+			Rows: [][]string{
+				{"0591-S00001", "12", "Überzählig"},
+				{"5678", "1", ""},
+			},
+		})
+
+		Expect(logger.InfoArgsForCall(0)).To(Equal("recorded equipment:"))
+		Expect(logger.InfoIndentedCallCount()).To(Equal(4))
+		Expect(logger.InfoIndentedArgsForCall(0)).To(Equal("equipment   : amount : note"))
+		Expect(logger.InfoIndentedArgsForCall(1)).To(Equal("---------------------------------"))
+		Expect(logger.InfoIndentedArgsForCall(2)).To(Equal("0591-S00001 :     12 : Überzählig"))
+		Expect(logger.InfoIndentedArgsForCall(3)).To(Equal("5678        :      1 : "))
+	})
+
+	It("should write warning tables as warnings", func() {
+		app.NewReporter(logger).Table(app.ReportTable{
+			Title:   "surplus:",
+			Columns: []app.ReportColumn{{Header: "equipment"}},
+			Rows:    [][]string{{"5678"}},
+			Warning: true,
+		})
+
+		Expect(logger.InfoCallCount()).To(Equal(0))
+		Expect(logger.WarnArgsForCall(0)).To(Equal("surplus:"))
+		Expect(logger.WarnIndentedCallCount()).To(Equal(3))
+	})
+
+	It("should not write tables without rows", func() {
+		app.NewReporter(logger).Table(app.ReportTable{
+			Title:   "surplus:",
+			Columns: []app.ReportColumn{{Header: "equipment"}},
+		})
+
+		Expect(logger.Invocations()).To(BeEmpty())
+	})
+
+	Describe("SortScanIDs", func() {
+		amounts := map[string]int{"b": 1, "a": 1, "c": 3}
+		origins := app.ScanOriginsMap{
+			"a": {{File: "scan2.csv", Line: 1}},
+			"b": {{File: "scan1.csv", Line: 2}},
+			"c": {{File: "scan1.csv", Line: 5}, {File: "scan1.csv", Line: 1}},
+		}
+
+		It("should sort by ID by default", func() {
+			Expect(app.SortScanIDs(amounts, origins, "")).To(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("should sort by the first scan", func() {
+			Expect(app.SortScanIDs(amounts, origins, config.ReportOrderFile)).To(Equal([]string{"b", "c", "a"}))
+		})
+
+		It("should sort by the highest amount", func() {
+			Expect(app.SortScanIDs(amounts, origins, config.ReportOrderAmount)).To(Equal([]string{"c", "a", "b"}))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// ScanCommandPrefix starts the command barcodes in the files with recorded equipment
+	ScanCommandPrefix = "CMD:"
+
+	ConditionDefective   = "defekt"
+	ConditionNeedsRepair = "reparaturbedürftig"
+
+	DefaultConditionColumn = "Zustand"
+	DefaultNoteColumn      = "Bemerkung"
+)
+
+// ScanAnnotation is a condition or note set by command barcodes for the next scan
+type ScanAnnotation struct {
+	Condition string     `json:"condition,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Origin    ScanOrigin `json:"origin"`
+}
+
+// ScanAnnotationMap holds the annotations per normalized equipment ID in file order
+type ScanAnnotationMap map[string][]ScanAnnotation
+
+// IgnoredScanCommand is a command barcode which is unknown or not followed by a scan
+type IgnoredScanCommand struct {
+	Command string
+	Problem string
+	Origin  ScanOrigin
+}
+
+// isScanCommand returns true for command barcodes like CMD:DEFEKT, ignoring case
+func isScanCommand(scan string) bool {
+	scan = strings.TrimSpace(scan)
+	return len(scan) >= len(ScanCommandPrefix) && strings.EqualFold(scan[:len(ScanCommandPrefix)], ScanCommandPrefix)
+}
+
+// scanCommand returns the command barcode of a scan from its prefix on. Scans are checked after
+// normalization, so commands behind an AIM identifier like ]C1CMD:DEFEKT are detected too, while
+// the text of a note is taken unchanged from the scan.
+func scanCommand(scan string, normalizer ScanNormalizer) (string, bool) {
+	normalized := normalizer.Normalize(scan)
+	if !isScanCommand(normalized) {
+		return "", false
+	}
+
+	for i := 0; i+len(ScanCommandPrefix) <= len(scan); i++ {
+		if strings.EqualFold(scan[i:i+len(ScanCommandPrefix)], ScanCommandPrefix) {
+			return strings.TrimSpace(scan[i:]), true
+		}
+	}
+
+	// the prefix was created by the normalization
+	return strings.TrimSpace(normalized), true
+}
+
+// parseScanCommand returns the annotation of the command barcodes CMD:DEFEKT, CMD:REPARATUR and CMD:NOTE:<text>
+func parseScanCommand(scan string) (ScanAnnotation, error) {
+	command := strings.TrimSpace(scan)[len(ScanCommandPrefix):]
+	name, argument, _ := strings.Cut(command, ":")
+
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEFEKT":
+		return ScanAnnotation{Condition: ConditionDefective}, nil
+	case "REPARATUR":
+		return ScanAnnotation{Condition: ConditionNeedsRepair}, nil
+	case "NOTE":
+		if strings.TrimSpace(argument) == "" {
+			return ScanAnnotation{}, fmt.Errorf("command '%s' has no text", scan)
+		}
+		return ScanAnnotation{Note: strings.TrimSpace(argument)}, nil
+	default:
+		return ScanAnnotation{}, fmt.Errorf("unknown command '%s'", scan)
+	}
+}
+
+// Conditions returns the distinct conditions of the annotations
+func (a ScanAnnotationMap) Conditions(id string) string {
+	var conditions []string
+	for _, annotation := range a[id] {
+		if annotation.Condition != "" && !slices.Contains(conditions, annotation.Condition) {
+			conditions = append(conditions, annotation.Condition)
+		}
+	}
+	return strings.Join(conditions, ", ")
+}
+
+// Notes returns the notes of the annotations
+func (a ScanAnnotationMap) Notes(id string) string {
+	var notes []string
+	for _, annotation := range a[id] {
+		if annotation.Note != "" {
+			notes = append(notes, annotation.Note)
+		}
+	}
+	return strings.Join(notes, "; ")
+}
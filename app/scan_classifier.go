@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"thwInventoryMerge/config"
+)
+
+type ScanClass string
+
+const (
+	// ScanClassValid matches one of the ID formats or no formats are configured
+	ScanClassValid ScanClass = "valid"
+
+	// ScanClassMalformed looks like an ID of a format but does not match it, e.g. a misread
+	ScanClassMalformed ScanClass = "malformed"
+
+	// ScanClassForeign matches none of the ID formats, e.g. a barcode of the manufacturer
+	ScanClassForeign ScanClass = "foreign"
+)
+
+// ScanClassification is the class of a scan and the name of the matching format
+type ScanClassification struct {
+	Class  ScanClass
+	Format string
+}
+
+type ScanClassMap map[string]ScanClassification
+
+// ScanClassifier checks scans against the configured ID formats, ignoring case
+type ScanClassifier interface {
+	Classify(scan string) ScanClassification
+}
+
+type idFormat struct {
+	name      string
+	pattern   *regexp.Regexp
+	candidate *regexp.Regexp
+}
+
+type scanClassifier struct {
+	formats []idFormat
+}
+
+func NewScanClassifier(config config.Config) (ScanClassifier, error) {
+	classifier := &scanClassifier{}
+
+	for _, format := range config.IDFormats {
+		pattern, err := regexp.Compile("(?i)" + format.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern of ID format '%s': %w", format.Name, err)
+		}
+
+		var candidate *regexp.Regexp
+		if format.Candidate != "" {
+			candidate, err = regexp.Compile("(?i)" + format.Candidate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile candidate of ID format '%s': %w", format.Name, err)
+			}
+		}
+
+		classifier.formats = append(classifier.formats, idFormat{name: format.Name, pattern: pattern, candidate: candidate})
+	}
+
+	return classifier, nil
+}
+
+func (c *scanClassifier) Classify(scan string) ScanClassification {
+	if len(c.formats) == 0 {
+		return ScanClassification{Class: ScanClassValid}
+	}
+
+	for _, format := range c.formats {
+		if format.pattern.MatchString(scan) {
+			return ScanClassification{Class: ScanClassValid, Format: format.name}
+		}
+	}
+
+	for _, format := range c.formats {
+		if format.candidate != nil && format.candidate.MatchString(scan) {
+			return ScanClassification{Class: ScanClassMalformed, Format: format.name}
+		}
+	}
+
+	return ScanClassification{Class: ScanClassForeign}
+}
@@ -0,0 +1,51 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScanClassifier", func() {
+
+	cfg := config.Config{
+		IDFormats: []config.ConfigIDFormat{
+			{Name: "inventory_number", Pattern: `^\d{4}-S?\d{6}$`, Candidate: `^\d{4}-`},
+			{Name: "part_number", Pattern: `^\d{4}[A-Z]\d{5}$`},
+			{Name: "pseudo_id", Pattern: `^P-`},
+		},
+	}
+
+	It("should treat all scans as valid without formats", func() {
+		classifier, err := app.NewScanClassifier(config.Config{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(classifier.Classify("0591-0027")).To(Equal(app.ScanClassification{Class: app.ScanClassValid}))
+	})
+
+	It("should classify scans as valid, malformed or foreign ignoring case", func() {
+		classifier, err := app.NewScanClassifier(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(classifier.Classify("0591-s002360")).To(Equal(app.ScanClassification{Class: app.ScanClassValid, Format: "inventory_number"}))
+		Expect(classifier.Classify("2540t21171")).To(Equal(app.ScanClassification{Class: app.ScanClassValid, Format: "part_number"}))
+		Expect(classifier.Classify("p-1234")).To(Equal(app.ScanClassification{Class: app.ScanClassValid, Format: "pseudo_id"}))
+		Expect(classifier.Classify("0591-0027")).To(Equal(app.ScanClassification{Class: app.ScanClassMalformed, Format: "inventory_number"}))
+		Expect(classifier.Classify("4006381333931")).To(Equal(app.ScanClassification{Class: app.ScanClassForeign}))
+	})
+
+	It("should classify the normalized recorded scans", func() {
+		classifier, err := app.NewScanClassifier(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		classes, err := app.NewRecordedInventory([]app.CSVContent{{{"0591-S002360"}, {"0591-0027"}}}).Classify(classifier)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(classes).To(Equal(app.ScanClassMap{
+			"0591-s002360": {Class: app.ScanClassValid, Format: "inventory_number"},
+			"0591-0027":    {Class: app.ScanClassMalformed, Format: "inventory_number"},
+		}))
+	})
+})
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+const bell = "\a"
+
+type ScanInventoryStep interface {
+	Scan() error
+}
+
+type inventoryScanner struct {
+	config config.Config
+	logger utils.Logger
+	input  io.Reader
+	output io.Writer
+}
+
+func NewScanInventoryStep(config config.Config, logger utils.Logger) ScanInventoryStep {
+	return NewScanInventoryStepWithIO(config, logger, os.Stdin, os.Stdout)
+}
+
+// NewScanInventoryStepWithIO reads the scans from input and writes the prompts and the equipment to output
+func NewScanInventoryStepWithIO(config config.Config, logger utils.Logger, input io.Reader, output io.Writer) ScanInventoryStep {
+	return &inventoryScanner{
+		config: config,
+		logger: logger,
+		input:  input,
+		output: output,
+	}
+}
+
+func (s *inventoryScanner) Scan() error {
+	csvFile := NewCSVFile(s.logger)
+
+	content, err := LoadInventory(s.config, s.logger)
+	if err != nil {
+		return err
+	}
+
+	inventoryData, err := NewInventoryData(content, s.config, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to init inventory data: %v", err)
+	}
+
+	normalizer, err := NewScanNormalizer(s.config)
+	if err != nil {
+		return err
+	}
+
+	counted, err := s.getRecordedInventory(csvFile, normalizer)
+	if err != nil {
+		return err
+	}
+
+	scanFilePath := filepath.Join(s.config.WorkingDir, fmt.Sprintf("scan_%s.csv", time.Now().Format("2006-01-02")))
+
+	// the file is created with the first scan, an empty file would fail the processing
+	var writer *csv.Writer
+
+	fmt.Fprintf(s.output, "Scans are appended to '%s'. Enter 'exit' or press Ctrl+D to stop.\n\n", scanFilePath)
+
+	scanner := bufio.NewScanner(s.input)
+	for {
+		fmt.Fprint(s.output, "> ")
+
+		if !scanner.Scan() {
+			break
+		}
+
+		scan := strings.TrimSpace(scanner.Text())
+		if scan == "" {
+			continue
+		}
+		if strings.EqualFold(scan, "exit") {
+			break
+		}
+
+		// an invalid command would be ignored by the processing of the scan file
+		command, isCommand := scanCommand(scan, normalizer)
+		if isCommand {
+			if _, err := parseScanCommand(command); err != nil {
+				fmt.Fprintf(s.output, "%s  !!! %v, the scan is ignored\n\n", bell, err)
+				continue
+			}
+		}
+
+		if writer == nil {
+			scanFile, err := os.OpenFile(scanFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open scan file '%s': %w", scanFilePath, err)
+			}
+			defer scanFile.Close()
+
+			writer = csv.NewWriter(scanFile)
+			writer.Comma = ';'
+		}
+
+		err = writer.Write([]string{scan})
+		if err == nil {
+			writer.Flush()
+			err = writer.Error()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write scan file '%s': %w", scanFilePath, err)
+		}
+
+		if isCommand {
+			s.printCommand(command)
+			continue
+		}
+
+		counted[normalizer.Key(scan)]++
+
+		s.printEquipment(scan, inventoryData.FindEquipment(scan), counted[normalizer.Key(scan)])
+	}
+
+	fmt.Fprintln(s.output)
+
+	return scanner.Err()
+}
+
+// getRecordedInventory returns the equipment recorded before this session
+func (s *inventoryScanner) getRecordedInventory(csvFile CSVFile, normalizer ScanNormalizer) (RecordedInventoryMap, error) {
+	csvFiles, err := s.config.GetCSVFilesWithRecordedEquipment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSV files: %v", err)
+	}
+
+	var recordedInventoryData []CSVContent
+	for _, file := range csvFiles {
+		encoding, err := NewEncodingProvider(s.logger).GetFileEncoding(file)
+		if err != nil {
+			// e.g. the empty scan file of a previous session
+			s.logger.Warn(fmt.Sprintf("skipping file '%s': %v", file, err))
+			continue
+		}
+
+		content, err := csvFile.Read(file, encoding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV file '%s': %v", file, err)
+		}
+		recordedInventoryData = append(recordedInventoryData, content)
+	}
+
+	storedScans, _, err := LoadStoredScans(s.config, csvFiles, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	recordedInventoryData = append(recordedInventoryData, storedScans...)
+
+	return NewRecordedInventoryFromFiles(recordedInventoryData, nil, normalizer).AsMap()
+}
+
+func (s *inventoryScanner) printCommand(command string) {
+	annotation, _ := parseScanCommand(command)
+	switch {
+	case annotation.Condition != "":
+		fmt.Fprintf(s.output, "  the next scan is marked as %s\n\n", annotation.Condition)
+	default:
+		fmt.Fprintf(s.output, "  the next scan gets the note '%s'\n\n", annotation.Note)
+	}
+}
+
+func (s *inventoryScanner) printEquipment(scan string, equipment []EquipmentInfo, counted int) {
+	if len(equipment) == 0 {
+		fmt.Fprintf(s.output, "%s  !!! %s is not available in the inventory (scanned %d times)\n\n", bell, scan, counted)
+		return
+	}
+
+	target := 0
+	targetKnown := true
+	for _, info := range equipment {
+		value, _, err := ParseQuantity(info.Target)
+		if err != nil || !IsPieceUnit(info.Unit) {
+			targetKnown = false
+			break
+		}
+		target += int(value)
+	}
+
+	for _, info := range equipment {
+		fmt.Fprintf(s.output, "  %s  %s (line %d)\n", info.ID, info.Description, info.Line)
+		if len(info.ParentPath) > 0 {
+			fmt.Fprintf(s.output, "  in: %s\n", strings.Join(info.ParentPath, " > "))
+		}
+	}
+
+	if !targetKnown {
+		fmt.Fprintf(s.output, "  counted: %d\n\n", counted)
+		return
+	}
+
+	if counted > target {
+		fmt.Fprintf(s.output, "%s  !!! expected: %d, counted: %d, over-counted\n\n", bell, target, counted)
+		return
+	}
+
+	fmt.Fprintf(s.output, "  expected: %d, counted: %d\n\n", target, counted)
+}
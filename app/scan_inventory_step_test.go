@@ -0,0 +1,105 @@
+package app_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScanInventoryStep", func() {
+
+	var (
+		tempDir string
+		cfg     *config.Config
+		logger  *utilsfakes.FakeLogger
+		output  strings.Builder
+	)
+
+	scanFilePath := func() string {
+		return filepath.Join(tempDir, fmt.Sprintf("scan_%s.csv", time.Now().Format("2006-01-02")))
+	}
+
+	scan := func(input string) error {
+		output.Reset()
+		return app.NewScanInventoryStepWithIO(*cfg, logger, strings.NewReader(input), &output).Scan()
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "scan-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		configPath := filepath.Join(tempDir, "config.json")
+		Expect(os.WriteFile(configPath, []byte(fmt.Sprintf(`{
+			"working_dir": %q,
+			"inventory_csv_file_name": "inventory.csv",
+			"columns": {
+				"equipment_layer": "Ebene",
+				"equipment_part_number": "Sachnummer",
+				"equipment_id": "Inventar Nr",
+				"equipment_count_actual": "Bestand IST",
+				"equipment_count_target": "Menge",
+				"equipment_description": "Ausstattung"
+			}
+		}`, tempDir)), 0644)).To(Succeed())
+
+		logger = &utilsfakes.FakeLogger{}
+		cfg, err = config.LoadConfig(configPath, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(app.NewCSVFile(logger).Write(cfg.GetAbsoluteInventoryCSVFileName(), app.CSVContent{
+			{"Ebene", "Sachnummer", "Ausstattung", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "1111", "Handlampe", "0591-S00001", "1", ""},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should append the scans to the file of the day and show the equipment", func() {
+		Expect(scan("0591-S00001\n\nCMD:DEFEKT\n0591-s00001\nexit\n0591-S00002\n")).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("0591-S00001  Handlampe (line 2)"))
+		Expect(output.String()).To(ContainSubstring("expected: 1, counted: 1\n"))
+		Expect(output.String()).To(ContainSubstring("the next scan is marked as defekt"))
+		Expect(output.String()).To(ContainSubstring("expected: 1, counted: 2, over-counted"))
+		Expect(output.String()).ToNot(ContainSubstring("0591-S00002"))
+
+		content, err := os.ReadFile(scanFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("0591-S00001\nCMD:DEFEKT\n0591-s00001\n"))
+	})
+
+	It("should count the scans of previous sessions and warn about unknown equipment", func() {
+		Expect(scan("0591-S00001\n")).To(Succeed())
+		Expect(scan("0591-S00001\n0591-S00002\n")).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("expected: 1, counted: 2, over-counted"))
+		Expect(output.String()).To(ContainSubstring("0591-S00002 is not available in the inventory (scanned 1 times)"))
+	})
+
+	It("should not save invalid commands", func() {
+		Expect(scan("CMD:KAPUTT\n0591-S00001\n")).To(Succeed())
+
+		Expect(output.String()).To(ContainSubstring("unknown command 'CMD:KAPUTT', the scan is ignored"))
+
+		content, err := os.ReadFile(scanFilePath())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("0591-S00001\n"))
+	})
+
+	It("should not create the scan file without scans", func() {
+		Expect(scan("\nexit\n")).To(Succeed())
+
+		Expect(scanFilePath()).ToNot(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"thwInventoryMerge/config"
+)
+
+// ScanNormalizer normalizes scans and inventory IDs, both are compared normalized
+type ScanNormalizer interface {
+	Normalize(value string) string
+
+	// Key returns the normalized value in lower case, scans only differing in case are counted together
+	Key(value string) string
+}
+
+type scanNormalizer struct {
+	steps []func(string) string
+}
+
+// NewScanNormalizer returns the normalizer of the configured rules, scans are lowercased if no rules are configured
+func NewScanNormalizer(config config.Config) (ScanNormalizer, error) {
+	rules := config.ScanNormalization
+	if len(rules) == 0 {
+		return DefaultScanNormalizer(), nil
+	}
+
+	normalizer := &scanNormalizer{}
+	for i, rule := range rules {
+		step, err := normalizationStep(rule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scan normalization rule %d: %w", i+1, err)
+		}
+		normalizer.steps = append(normalizer.steps, step)
+	}
+
+	return normalizer, nil
+}
+
+// DefaultScanNormalizer lowercases the scans
+func DefaultScanNormalizer() ScanNormalizer {
+	return &scanNormalizer{steps: []func(string) string{strings.ToLower}}
+}
+
+func (n *scanNormalizer) Normalize(value string) string {
+	for _, step := range n.steps {
+		value = step(value)
+	}
+	return value
+}
+
+func (n *scanNormalizer) Key(value string) string {
+	return strings.ToLower(n.Normalize(value))
+}
+
+func normalizationStep(rule config.ConfigNormalizationRule) (func(string) string, error) {
+	switch rule.Type {
+	case config.NormalizationTrim:
+		return strings.TrimSpace, nil
+	case config.NormalizationStripPrefix:
+		return func(value string) string { return strings.TrimPrefix(value, rule.Value) }, nil
+	case config.NormalizationStripSuffix:
+		return func(value string) string { return strings.TrimSuffix(value, rule.Value) }, nil
+	case config.NormalizationReplace:
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(value string) string { return pattern.ReplaceAllString(value, rule.Replacement) }, nil
+	case config.NormalizationPadZeros:
+		return func(value string) string { return padLastNumber(value, rule.Width) }, nil
+	case config.NormalizationLower:
+		return strings.ToLower, nil
+	case config.NormalizationUpper:
+		return strings.ToUpper, nil
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", rule.Type)
+	}
+}
+
+// padLastNumber pads the last number of the value with leading zeros, e.g. 0591-27 to 0591-00027 for width 5
+func padLastNumber(value string, width int) string {
+	end := len(value)
+	for end > 0 && !isDigit(value[end-1]) {
+		end--
+	}
+	start := end
+	for start > 0 && isDigit(value[start-1]) {
+		start--
+	}
+	if start == end || end-start >= width {
+		return value
+	}
+	return value[:start] + strings.Repeat("0", width-(end-start)) + value[start:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScanNormalizer", func() {
+
+	It("should lowercase the scans by default", func() {
+		normalizer, err := app.NewScanNormalizer(config.Config{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(normalizer.Normalize(" 0591-S00001")).To(Equal(" 0591-s00001"))
+	})
+
+	It("should apply the rules in order", func() {
+		normalizer, err := app.NewScanNormalizer(config.Config{
+			ScanNormalization: []config.ConfigNormalizationRule{
+				{Type: config.NormalizationTrim},
+				{Type: config.NormalizationReplace, Pattern: `^\][A-Za-z][0-9]`},
+				{Type: config.NormalizationStripPrefix, Value: "THW:"},
+				{Type: config.NormalizationStripSuffix, Value: "#"},
+				{Type: config.NormalizationPadZeros, Width: 6},
+				{Type: config.NormalizationUpper},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(normalizer.Normalize(" ]C1THW:0591-s2360# ")).To(Equal("0591-S002360"))
+		Expect(normalizer.Normalize("0591-002360")).To(Equal("0591-002360"))
+		Expect(normalizer.Normalize("0591-1234567")).To(Equal("0591-1234567"))
+		Expect(normalizer.Normalize("ABC")).To(Equal("ABC"))
+	})
+
+	It("should replace with groups", func() {
+		normalizer, err := app.NewScanNormalizer(config.Config{
+			ScanNormalization: []config.ConfigNormalizationRule{
+				{Type: config.NormalizationReplace, Pattern: `^(\d{4})(\d{6})$`, Replacement: "${1}-${2}"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(normalizer.Normalize("0591002360")).To(Equal("0591-002360"))
+	})
+
+	It("should count scans only differing in case together without case rule", func() {
+		cfg := config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+			},
+			ScanNormalization: []config.ConfigNormalizationRule{
+				{Type: config.NormalizationTrim},
+			},
+		}
+		normalizer, err := app.NewScanNormalizer(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		recordedInventory := app.NewRecordedInventoryFromFiles([]app.CSVContent{{{"0591-s1"}, {" 0591-S1"}, {"0591-S1"}}}, nil, normalizer)
+		inventoryMap, err := recordedInventory.AsMap()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inventoryMap).To(Equal(app.RecordedInventoryMap{"0591-s1": 3}))
+
+		inventoryData, err := app.NewInventoryData([][]string{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "123", "0591-S1", ""},
+		}, cfg, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(inventoryData.UpdateInventory(inventoryMap, nil)).To(Succeed())
+		Expect(inventoryData.GetContent()[1][3]).To(Equal("3"))
+	})
+
+	It("should match normalized scans with normalized inventory IDs", func() {
+		cfg := config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+			},
+			ScanNormalization: []config.ConfigNormalizationRule{
+				{Type: config.NormalizationTrim},
+				{Type: config.NormalizationPadZeros, Width: 6},
+				{Type: config.NormalizationLower},
+			},
+		}
+		normalizer, err := app.NewScanNormalizer(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		inventoryData, err := app.NewInventoryData([][]string{
+			{"Ebene", "Sachnummer", "Inventar Nr", "Bestand IST"},
+			{"1", "123", "0591-S2360 ", ""},
+		}, cfg, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		recordedInventory := app.NewRecordedInventoryFromFiles([]app.CSVContent{{{"0591-s002360"}, {" 0591-S2360"}}}, nil, normalizer)
+		inventoryMap, err := recordedInventory.AsMap()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inventoryMap).To(Equal(app.RecordedInventoryMap{"0591-s002360": 2}))
+
+		Expect(inventoryData.FindEquipment("0591-S02360")).To(HaveLen(1))
+
+		Expect(inventoryData.UpdateInventory(inventoryMap, nil)).To(Succeed())
+		Expect(inventoryData.GetContent()[1][3]).To(Equal("2"))
+	})
+})
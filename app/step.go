@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+// StepContext is passed to a running step
+type StepContext struct {
+	context.Context
+	Config config.Config
+	Logger utils.Logger
+}
+
+// StepFlag is a command line flag of a step, flags with the same name are shared by the steps
+type StepFlag struct {
+	Name  string
+	Usage string
+
+	// Apply sets the value in the config, it is called only if the flag is given
+	Apply func(config *config.Config, value string)
+}
+
+type Step interface {
+	Name() string
+
+	Description() string
+
+	Flags() []StepFlag
+
+	Run(ctx StepContext) error
+}
+
+type step struct {
+	name        string
+	description string
+	flags       []StepFlag
+	run         func(ctx StepContext) error
+}
+
+func NewStep(name string, description string, flags []StepFlag, run func(ctx StepContext) error) Step {
+	return &step{
+		name:        name,
+		description: description,
+		flags:       flags,
+		run:         run,
+	}
+}
+
+func (s *step) Name() string {
+	return s.name
+}
+
+func (s *step) Description() string {
+	return s.description
+}
+
+func (s *step) Flags() []StepFlag {
+	return s.flags
+}
+
+func (s *step) Run(ctx StepContext) error {
+	return s.run(ctx)
+}
+
+type StepRegistry interface {
+	Register(step Step) error
+
+	// Steps returns the steps in the order of registration
+	Steps() []Step
+
+	// Flags returns the flags of all steps, each name once
+	Flags() []StepFlag
+
+	// Resolve returns the steps of a comma separated list like "init,process"
+	Resolve(names string) ([]Step, error)
+}
+
+type stepRegistry struct {
+	steps []Step
+}
+
+func NewStepRegistry() StepRegistry {
+	return &stepRegistry{}
+}
+
+func (r *stepRegistry) Register(step Step) error {
+	if r.get(step.Name()) != nil {
+		return fmt.Errorf("step '%s' is registered already", step.Name())
+	}
+	r.steps = append(r.steps, step)
+	return nil
+}
+
+func (r *stepRegistry) Steps() []Step {
+	return r.steps
+}
+
+func (r *stepRegistry) Flags() []StepFlag {
+	var flags []StepFlag
+	known := make(map[string]bool)
+
+	for _, step := range r.steps {
+		for _, flag := range step.Flags() {
+			if !known[flag.Name] {
+				known[flag.Name] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+
+	return flags
+}
+
+func (r *stepRegistry) Resolve(names string) ([]Step, error) {
+	var steps []Step
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+
+		step := r.get(name)
+		if step == nil {
+			return nil, fmt.Errorf("invalid step: %s", name)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+func (r *stepRegistry) get(name string) Step {
+	for _, step := range r.steps {
+		if step.Name() == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// RunSteps runs the steps one after another with the flag values given on the command line and stops at the first error
+func RunSteps(ctx context.Context, steps []Step, flagValues map[string]string, config config.Config, logger utils.Logger) error {
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		stepConfig := config
+		for _, flag := range step.Flags() {
+			if value := flagValues[flag.Name]; value != "" {
+				flag.Apply(&stepConfig, value)
+			}
+		}
+
+		logger.Info(fmt.Sprintf("running %s step", step.Name()))
+
+		err := step.Run(StepContext{
+			Context: ctx,
+			Config:  stepConfig,
+			Logger:  logger,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to run %s step: %w", step.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Step", func() {
+
+	var (
+		registry app.StepRegistry
+		runs     []string
+	)
+
+	baselineFlag := app.StepFlag{
+		Name: "b",
+		Apply: func(config *config.Config, value string) {
+			config.BaselineResult = value
+		},
+	}
+
+	newStep := func(name string, flags []app.StepFlag, err error) app.Step {
+		return app.NewStep(name, name+" description", flags, func(ctx app.StepContext) error {
+			runs = append(runs, name+":"+ctx.Config.BaselineResult)
+			return err
+		})
+	}
+
+	BeforeEach(func() {
+		runs = nil
+		registry = app.NewStepRegistry()
+
+		Expect(registry.Register(newStep("init", nil, nil))).To(Succeed())
+		Expect(registry.Register(newStep("process", []app.StepFlag{baselineFlag}, nil))).To(Succeed())
+		Expect(registry.Register(newStep("migrate", []app.StepFlag{{Name: "e"}, baselineFlag}, nil))).To(Succeed())
+		Expect(registry.Register(newStep("fail", nil, errors.New("boom")))).To(Succeed())
+	})
+
+	It("should not register a step twice", func() {
+		err := registry.Register(newStep("init", nil, nil))
+		Expect(err).To(MatchError("step 'init' is registered already"))
+	})
+
+	It("should return the steps in the order of registration", func() {
+		var names []string
+		for _, step := range registry.Steps() {
+			names = append(names, step.Name())
+		}
+		Expect(names).To(Equal([]string{"init", "process", "migrate", "fail"}))
+	})
+
+	It("should return each flag once", func() {
+		var names []string
+		for _, flag := range registry.Flags() {
+			names = append(names, flag.Name)
+		}
+		Expect(names).To(Equal([]string{"b", "e"}))
+	})
+
+	It("should resolve a comma separated list of steps", func() {
+		steps, err := registry.Resolve("process, init")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(steps).To(HaveLen(2))
+		Expect(steps[0].Name()).To(Equal("process"))
+		Expect(steps[1].Name()).To(Equal("init"))
+
+		_, err = registry.Resolve("init,foo")
+		Expect(err).To(MatchError("invalid step: foo"))
+	})
+
+	It("should run the steps with their flags", func() {
+		steps, err := registry.Resolve("init,process")
+		Expect(err).ToNot(HaveOccurred())
+
+		logger := &utilsfakes.FakeLogger{}
+
+		err = app.RunSteps(context.Background(), steps, map[string]string{"b": "latest"}, config.Config{}, logger)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(Equal([]string{"init:", "process:latest"}))
+		Expect(logger.InfoArgsForCall(0)).To(Equal("running init step"))
+		Expect(logger.InfoArgsForCall(1)).To(Equal("running process step"))
+	})
+
+	It("should stop at the first failing step", func() {
+		steps, err := registry.Resolve("init,fail,process")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = app.RunSteps(context.Background(), steps, nil, config.Config{}, &utilsfakes.FakeLogger{})
+		Expect(err).To(MatchError("failed to run fail step: boom"))
+		Expect(runs).To(Equal([]string{"init:", "fail:"}))
+	})
+
+	It("should not run steps if the context is done", func() {
+		steps, err := registry.Resolve("init")
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = app.RunSteps(ctx, steps, nil, config.Config{}, &utilsfakes.FakeLogger{})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(runs).To(BeEmpty())
+	})
+})
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"thwInventoryMerge/config"
+)
+
+var baselineResultFlag = StepFlag{
+	Name:  "b",
+	Usage: "the result file used as baseline, 'latest' for the most recent result",
+	Apply: func(config *config.Config, value string) {
+		config.BaselineResult = value
+	},
+}
+
+var migrationExportFlag = StepFlag{
+	Name:  "e",
+	Usage: "the new THWin export used by the migrate step",
+	Apply: func(config *config.Config, value string) {
+		config.MigrationExport = value
+	},
+}
+
+// NewDefaultStepRegistry returns a registry with all steps of the inventory
+func NewDefaultStepRegistry() StepRegistry {
+	registry := NewStepRegistry()
+
+	steps := []Step{
+		NewStep("init", "prepares the THWin export with pseudo IDs and the actual count column", nil, func(ctx StepContext) error {
+			return NewInitInventoryCSVStep(ctx.Config, ctx.Logger).Init()
+		}),
+		NewStep("validate", "checks the THWin export for problems", nil, func(ctx StepContext) error {
+			return NewValidateInventoryStep(ctx.Config, ctx.Logger).Validate()
+		}),
+		NewStep("process", "merges the recorded equipment into the inventory", []StepFlag{baselineResultFlag}, func(ctx StepContext) error {
+			_, err := NewProcessInvetoryStep(ctx.Config, ctx.Logger).Process()
+			return err
+		}),
+		NewStep("scan", "records scanned equipment in the terminal", nil, func(ctx StepContext) error {
+			return NewScanInventoryStep(ctx.Config, ctx.Logger).Scan()
+		}),
+		NewStep("watch", "processes the inventory whenever recorded equipment changes", []StepFlag{baselineResultFlag}, func(ctx StepContext) error {
+			return NewWatchInventoryStep(ctx.Config, ctx.Logger).Watch(ctx)
+		}),
+		NewStep("report", "prints the statistics of the latest result", nil, func(ctx StepContext) error {
+			return NewReportInventoryStep(ctx.Config, ctx.Logger).Report()
+		}),
+		NewStep("export", "exports the latest result for the THWin import", nil, func(ctx StepContext) error {
+			return NewExportInventoryStep(ctx.Config, ctx.Logger).Export()
+		}),
+		NewStep("migrate", "carries the counts over into a new THWin export", []StepFlag{migrationExportFlag, baselineResultFlag}, func(ctx StepContext) error {
+			return NewMigrateInventoryStep(ctx.Config, ctx.Logger).Migrate()
+		}),
+	}
+
+	for _, step := range steps {
+		// the names are unique
+		_ = registry.Register(step)
+	}
+
+	return registry
+}
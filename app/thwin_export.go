@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+var defaultTHWinExportColumns = []config.ConfigExportColumn{
+	{Header: "Inventar Nr", Value: "{{.ID}}"},
+	{Header: "Sachnummer", Value: "{{.PartNumber}}"},
+	{Header: "Menge Ist", Value: "{{.Actual}}"},
+}
+
+// THWinExportRow is the data available in the column templates of the THWin export
+type THWinExportRow struct {
+	ID          string
+	PartNumber  string
+	Description string
+	ParentID    string
+	Target      Quantity
+	Actual      Quantity
+}
+
+type THWinExport interface {
+	// Export returns the counted equipment in the format of the THWin inventory import
+	Export(inventoryData InventoryData) (CSVContent, error)
+}
+
+type thwinExport struct {
+	headers   []string
+	templates []*template.Template
+	pseudoIDs PseudoIDScheme
+	logger    utils.Logger
+}
+
+func NewTHWinExport(config config.Config, logger utils.Logger) (THWinExport, error) {
+	columns := config.THWinExportColumns
+	if len(columns) == 0 {
+		columns = defaultTHWinExportColumns
+	}
+
+	pseudoIDs, err := NewPseudoIDScheme(config)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &thwinExport{
+		pseudoIDs: pseudoIDs,
+		logger:    logger,
+	}
+
+	for _, column := range columns {
+		tmpl, err := template.New(column.Header).Option("missingkey=error").Parse(column.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template of export column '%s': %w", column.Header, err)
+		}
+
+		export.headers = append(export.headers, column.Header)
+		export.templates = append(export.templates, tmpl)
+	}
+
+	return export, nil
+}
+
+func (e *thwinExport) Export(inventoryData InventoryData) (CSVContent, error) {
+	var rows []*THWinExportRow
+	aggregated := make(map[string]*THWinExportRow)
+
+	for _, info := range inventoryData.GetEquipment() {
+		actual, _, err := ParseQuantity(info.Actual)
+		if err != nil {
+			// not counted
+			continue
+		}
+		target, _, _ := ParseQuantity(info.Target)
+
+		if e.pseudoIDs.IsRealID(info.ID) {
+			rows = append(rows, &THWinExportRow{
+				ID:          info.ID,
+				PartNumber:  info.PartNumber,
+				Description: info.Description,
+				ParentID:    info.ParentID,
+				Target:      target,
+				Actual:      actual,
+			})
+			continue
+		}
+
+		if !e.pseudoIDs.IsPseudoID(info.ID) {
+			e.logger.Warn(fmt.Sprintf("skipping line %d of the export, '%s' is no equipment ID", info.Line, info.ID))
+			continue
+		}
+
+		// equipment without inventory number is aggregated by part number per parent
+		key := info.ParentID + "\x00" + info.PartNumber
+		if row, ok := aggregated[key]; ok {
+			row.Target += target
+			row.Actual += actual
+			continue
+		}
+
+		row := &THWinExportRow{
+			PartNumber:  info.PartNumber,
+			Description: info.Description,
+			ParentID:    info.ParentID,
+			Target:      target,
+			Actual:      actual,
+		}
+		aggregated[key] = row
+		rows = append(rows, row)
+	}
+
+	content := CSVContent{e.headers}
+
+	for _, row := range rows {
+		var record []string
+
+		for i, tmpl := range e.templates {
+			var value strings.Builder
+
+			err := tmpl.Execute(&value, row)
+			if err != nil {
+				return nil, fmt.Errorf("failed to execute template of export column '%s': %w", e.headers[i], err)
+			}
+
+			record = append(record, value.String())
+		}
+
+		content = append(content, record)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,95 @@
+package app_test
+
+import (
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("THWinExport", func() {
+
+	var (
+		logger        *utilsfakes.FakeLogger
+		cfg           config.Config
+		inventoryData app.InventoryData
+	)
+
+	BeforeEach(func() {
+		logger = &utilsfakes.FakeLogger{}
+		cfg = config.Config{
+			Columns: config.ConfigColumns{
+				EquipmentLayer:       "Ebene",
+				EquipmentPartNumber:  "Sachnummer",
+				EquipmentID:          "Inventar Nr",
+				EquipmentCountActual: "Bestand IST",
+				EquipmentCountTarget: "Menge",
+				EquipmentDescription: "Ausstattung",
+			},
+		}
+
+		var err error
+		inventoryData, err = app.NewInventoryData([][]string{
+			{"Ebene", "Ausstattung", "Sachnummer", "Inventar Nr", "Menge", "Bestand IST"},
+			{"1", "Werkzeugkasten", "1111", "5678", "1", "1"},
+			{"2", "Spanngurt", "2222", "5678__2222", "2", "2"},
+			{"2", "Spanngurt", "2222", "5678__2222", "3", "1"},
+			{"2", "Handlampe", "3333", "5679", "1", ""},
+			{"2", "Einsatz", "4444", "---------------", "1", "1"},
+			{"1", "Rettungsweste", "5555", "5680", "1", "0"},
+		}, cfg, logger)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("exports counted real inventory numbers and aggregates pseudo IDs by part number", func() {
+		thwinExport, err := app.NewTHWinExport(cfg, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := thwinExport.Export(inventoryData)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(content).To(Equal(app.CSVContent{
+			{"Inventar Nr", "Sachnummer", "Menge Ist"},
+			{"5678", "1111", "1"},
+			{"", "2222", "3"},
+			{"5680", "5555", "0"},
+		}))
+
+		Expect(logger.WarnCallCount()).To(Equal(1))
+		Expect(logger.WarnArgsForCall(0)).To(Equal("skipping line 6 of the export, '---------------' is no equipment ID"))
+	})
+
+	It("uses the configured column template", func() {
+		cfg.THWinExportColumns = []config.ConfigExportColumn{
+			{Header: "InvNr", Value: "{{if .ID}}{{.ID}}{{else}}{{.ParentID}}{{end}}"},
+			{Header: "SNr", Value: "{{.PartNumber}}"},
+			{Header: "Bezeichnung", Value: "{{.Description}}"},
+			{Header: "Ist/Soll", Value: "{{.Actual}}/{{.Target}}"},
+		}
+
+		thwinExport, err := app.NewTHWinExport(cfg, logger)
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := thwinExport.Export(inventoryData)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(content).To(Equal(app.CSVContent{
+			{"InvNr", "SNr", "Bezeichnung", "Ist/Soll"},
+			{"5678", "1111", "Werkzeugkasten", "1/1"},
+			{"5678", "2222", "Spanngurt", "3/5"},
+			{"5680", "5555", "Rettungsweste", "0/1"},
+		}))
+	})
+
+	It("returns an error for an invalid column template", func() {
+		cfg.THWinExportColumns = []config.ConfigExportColumn{
+			{Header: "InvNr", Value: "{{.ID"},
+		}
+
+		_, err := app.NewTHWinExport(cfg, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to parse template of export column 'InvNr'"))
+	})
+})
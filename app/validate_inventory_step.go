@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+)
+
+type ValidateInventoryStep interface {
+	// Validate returns an error if the inventory file has problems
+	Validate() error
+}
+
+type inventoryValidationStep struct {
+	config config.Config
+	logger utils.Logger
+}
+
+func NewValidateInventoryStep(config config.Config, logger utils.Logger) ValidateInventoryStep {
+	return &inventoryValidationStep{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (s *inventoryValidationStep) Validate() error {
+	filePath := s.config.GetAbsoluteInventoryCSVFileName()
+
+	content, err := ReadInventoryFile(filePath, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info(fmt.Sprintf("validating '%s'", filePath))
+
+	validator, err := NewInventoryValidator(s.config, s.logger)
+	if err != nil {
+		return err
+	}
+
+	report := validator.Validate(content)
+	report.Log(s.logger)
+
+	if len(report) > 0 {
+		return fmt.Errorf("inventory file '%s' has %d problems", filePath, len(report))
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils"
+	"time"
+)
+
+const (
+	watchPollInterval = 2 * time.Second
+	watchDebounce     = 5 * time.Second
+)
+
+type WatchInventoryStep interface {
+	// Watch runs until the context is done or Ctrl+C is pressed
+	Watch(ctx context.Context) error
+}
+
+type inventoryWatcher struct {
+	config       config.Config
+	logger       utils.Logger
+	process      func() (InventoryStatistics, error)
+	pollInterval time.Duration
+	debounce     time.Duration
+}
+
+func NewWatchInventoryStep(config config.Config, logger utils.Logger) WatchInventoryStep {
+	return NewWatchInventoryStepWithProcess(config, logger, NewProcessInvetoryStep(config, logger).Process, watchPollInterval, watchDebounce)
+}
+
+// NewWatchInventoryStepWithProcess polls the files with recorded equipment every pollInterval and calls
+// process once they did not change for the debounce duration
+func NewWatchInventoryStepWithProcess(config config.Config, logger utils.Logger, process func() (InventoryStatistics, error), pollInterval time.Duration, debounce time.Duration) WatchInventoryStep {
+	return &inventoryWatcher{
+		config:       config,
+		logger:       logger,
+		process:      process,
+		pollInterval: pollInterval,
+		debounce:     debounce,
+	}
+}
+
+// fileState is used to detect new or changed files with recorded equipment
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// watchState remembers the files of the last poll and of the last processing
+type watchState struct {
+	polled    map[string]fileState
+	changed   time.Time
+	processed map[string]fileState
+
+	// failed files are processed again once they change
+	failed map[string]fileState
+}
+
+// due returns true if the files differ from the processed ones and did not change for the debounce
+// duration, the first processing does not wait
+func (s *watchState) due(current map[string]fileState, now time.Time, debounce time.Duration) bool {
+	if !equalFileStates(current, s.polled) {
+		s.polled = current
+		s.changed = now
+	}
+
+	if equalFileStates(current, s.processed) || equalFileStates(current, s.failed) {
+		return false
+	}
+
+	return (s.processed == nil && s.failed == nil) || now.Sub(s.changed) >= debounce
+}
+
+func (s *watchState) finish(current map[string]fileState, err error) {
+	if err != nil {
+		s.failed = current
+		return
+	}
+	s.processed = current
+	s.failed = nil
+}
+
+func (w *inventoryWatcher) Watch(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	w.logger.Info(fmt.Sprintf("watching '%s' for files with recorded equipment, press Ctrl+C to stop", w.config.WorkingDir))
+	w.logger.Info("")
+
+	var state watchState
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		current, err := w.getFileStates()
+		if err != nil {
+			return err
+		}
+
+		if state.due(current, time.Now(), w.debounce) {
+			statistics, err := w.process()
+			if err != nil {
+				w.logger.Error(fmt.Sprintf("failed to process inventory: %v", err))
+			} else {
+				w.printDashboard(current, statistics)
+			}
+
+			state.finish(current, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("stopped watching")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *inventoryWatcher) getFileStates() (map[string]fileState, error) {
+	files, err := w.config.ListCSVFilesWithRecordedEquipment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSV files: %v", err)
+	}
+
+	states := make(map[string]fileState)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			// the file was removed in the meantime
+			continue
+		}
+		states[file] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return states, nil
+}
+
+func equalFileStates(a, b map[string]fileState) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for file, state := range a {
+		if other, ok := b[file]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *inventoryWatcher) printDashboard(files map[string]fileState, statistics InventoryStatistics) {
+	w.logger.Info(fmt.Sprintf("==== %s ====", time.Now().Format("2006-01-02 15:04:05")))
+	w.logger.InfoIndented(fmt.Sprintf("files with recorded equipment : %5d", len(files)))
+	w.logger.InfoIndented(fmt.Sprintf("rows fully counted            : %5d / %5d (%5.1f %%)", statistics.FullyCounted, statistics.RowsWithTarget, percentage(statistics.FullyCounted, statistics.RowsWithTarget)))
+	w.logger.InfoIndented(fmt.Sprintf("items counted                 : %5d / %5d (%5.1f %%)", statistics.CountedItems, statistics.Items, statistics.Coverage()))
+	w.logger.Info("")
+}
+
+func percentage(value, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) * 100 / float64(total)
+}
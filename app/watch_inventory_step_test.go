@@ -0,0 +1,130 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"thwInventoryMerge/app"
+	"thwInventoryMerge/config"
+	"thwInventoryMerge/utils/utilsfakes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WatchInventoryStep", func() {
+
+	const debounce = 200 * time.Millisecond
+
+	var (
+		tempDir string
+		logger  *utilsfakes.FakeLogger
+		calls   atomic.Int32
+		failing atomic.Bool
+		cancel  context.CancelFunc
+		done    chan error
+	)
+
+	process := func() (app.InventoryStatistics, error) {
+		calls.Add(1)
+		if failing.Load() {
+			return app.InventoryStatistics{}, errors.New("broken scan file")
+		}
+		return app.InventoryStatistics{InventoryCounts: app.InventoryCounts{Items: 4, CountedItems: 3, RowsWithTarget: 2, FullyCounted: 1}}, nil
+	}
+
+	appendScan := func(fileName string, scan string) {
+		file, err := os.OpenFile(filepath.Join(tempDir, fileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		defer file.Close()
+
+		_, err = file.WriteString(scan + "\n")
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	watch := func() {
+		cfg := config.Config{WorkingDir: tempDir, InventoryCSVFileName: "inventory.csv"}
+		step := app.NewWatchInventoryStepWithProcess(cfg, logger, process, 10*time.Millisecond, debounce)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan error)
+		go func() {
+			done <- step.Watch(ctx)
+		}()
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = os.MkdirTemp("", "watch-inventory")
+		Expect(err).ToNot(HaveOccurred())
+
+		logger = &utilsfakes.FakeLogger{}
+		calls.Store(0)
+		failing.Store(false)
+
+		appendScan("inventory.csv", "Inventar Nr")
+		appendScan("scan_1.csv", "0591-S00001")
+	})
+
+	AfterEach(func() {
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		os.RemoveAll(tempDir)
+	})
+
+	It("should process the files at start and print the dashboard", func() {
+		watch()
+
+		Eventually(calls.Load).Should(BeEquivalentTo(1))
+		Eventually(logger.InfoIndentedCallCount).Should(Equal(3))
+
+		Expect(logger.InfoIndentedArgsForCall(0)).To(Equal("files with recorded equipment :     1"))
+		Expect(logger.InfoIndentedArgsForCall(1)).To(Equal("rows fully counted            :     1 /     2 ( 50.0 %)"))
+		Expect(logger.InfoIndentedArgsForCall(2)).To(Equal("items counted                 :     3 /     4 ( 75.0 %)"))
+	})
+
+	It("should not process unchanged files again", func() {
+		watch()
+
+		Eventually(calls.Load).Should(BeEquivalentTo(1))
+		Consistently(calls.Load, 3*debounce).Should(BeEquivalentTo(1))
+	})
+
+	It("should wait until the files did not change for the debounce duration", func() {
+		watch()
+		Eventually(calls.Load).Should(BeEquivalentTo(1))
+
+		// keep changing the files for longer than the debounce duration
+		for i := 0; i < 8; i++ {
+			appendScan("scan_1.csv", "0591-S00001")
+			appendScan("scan_2.csv", "0591-S00002")
+			time.Sleep(debounce / 4)
+		}
+		Expect(calls.Load()).To(BeEquivalentTo(1))
+
+		Eventually(calls.Load, 4*debounce).Should(BeEquivalentTo(2))
+		Consistently(calls.Load, 2*debounce).Should(BeEquivalentTo(2))
+		Expect(logger.InfoIndentedArgsForCall(3)).To(Equal("files with recorded equipment :     2"))
+	})
+
+	It("should process failed files again only after they changed", func() {
+		failing.Store(true)
+		watch()
+
+		Eventually(calls.Load).Should(BeEquivalentTo(1))
+		Consistently(calls.Load, 3*debounce).Should(BeEquivalentTo(1))
+		Expect(logger.ErrorArgsForCall(0)).To(Equal("failed to process inventory: broken scan file"))
+		Expect(logger.InfoIndentedCallCount()).To(Equal(0))
+
+		failing.Store(false)
+		appendScan("scan_1.csv", "0591-S00002")
+
+		Eventually(calls.Load, 4*debounce).Should(BeEquivalentTo(2))
+		Eventually(logger.InfoIndentedCallCount).Should(Equal(3))
+		Consistently(calls.Load, 2*debounce).Should(BeEquivalentTo(2))
+	})
+})
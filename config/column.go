@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"thwInventoryMerge/utils"
+)
+
+// ConfigColumn selects a column of the inventory by one of several header names, a regular expression or its index
+type ConfigColumn struct {
+	Names   []string
+	Pattern *regexp.Regexp
+
+	// Index starts with 1 for the first column, 0 if not used
+	Index int
+}
+
+// UnmarshalJSON accepts a header name, a list of header names, an index like 3 or an object like {"pattern": "^Inventar"}
+func (c *ConfigColumn) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		*c = ConfigColumn{Names: []string{name}}
+		return nil
+	}
+
+	var names []string
+	if json.Unmarshal(data, &names) == nil {
+		if len(names) == 0 {
+			return errors.New("column needs at least one name")
+		}
+		*c = ConfigColumn{Names: names}
+		return nil
+	}
+
+	var index int
+	if json.Unmarshal(data, &index) == nil {
+		if index < 1 {
+			return fmt.Errorf("column index %d is less than 1", index)
+		}
+		*c = ConfigColumn{Index: index}
+		return nil
+	}
+
+	var object struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil || object.Pattern == "" {
+		return fmt.Errorf("column must be a name, a list of names, an index or an object with a pattern: %s", data)
+	}
+
+	pattern, err := regexp.Compile(object.Pattern)
+	if err != nil {
+		return fmt.Errorf("column pattern '%s' is invalid: %w", object.Pattern, err)
+	}
+	*c = ConfigColumn{Pattern: pattern}
+
+	return nil
+}
+
+// Match returns the index of the first matching header, names are compared normalized and
+// patterns are matched against the cleaned header, see utils.NormalizeHeader and utils.CleanHeader
+func (c ConfigColumn) Match(header []string) (int, bool) {
+	switch {
+	case c.Index > 0:
+		return c.Index - 1, c.Index <= len(header)
+	case c.Pattern != nil:
+		for i, colName := range header {
+			if c.Pattern.MatchString(utils.CleanHeader(colName)) {
+				return i, true
+			}
+		}
+	default:
+		for _, name := range c.Names {
+			for i, colName := range header {
+				if utils.EqualHeaders(colName, name) {
+					return i, true
+				}
+			}
+		}
+	}
+	return -1, false
+}
+
+// Name returns the header name used if the column does not exist yet, e.g. the actual count added by init,
+// which is therefore only selected by names
+func (c ConfigColumn) Name() string {
+	switch {
+	case c.Index > 0:
+		return fmt.Sprintf("#%d", c.Index)
+	case c.Pattern != nil:
+		return c.Pattern.String()
+	case len(c.Names) > 0:
+		return c.Names[0]
+	default:
+		return ""
+	}
+}
+
+func (c ConfigColumn) String() string {
+	switch {
+	case c.Index > 0:
+		return fmt.Sprintf("index %d", c.Index)
+	case c.Pattern != nil:
+		return fmt.Sprintf("pattern '%s'", c.Pattern)
+	default:
+		return "'" + strings.Join(c.Names, "' or '") + "'"
+	}
+}
+
+// MissingColumnsError lists the configured columns not found in a header
+type MissingColumnsError struct {
+	Columns []string
+	Header  []string
+}
+
+func (e *MissingColumnsError) Error() string {
+	return fmt.Sprintf("%s not found, available columns: '%s'", strings.Join(e.Columns, ", "), strings.Join(e.Header, "', '"))
+}
+
+// Resolve returns the columns with the names of the matching headers, the computed columns are not
+// resolved. Columns without match keep their name and are listed in a MissingColumnsError, except the
+// actual count which is added by the init step.
+func (c ConfigColumns) Resolve(header []string) (ConfigColumns, error) {
+	resolved := c
+	var missing []string
+
+	for _, column := range resolved.inputColumns() {
+		if *column.name == "" {
+			continue
+		}
+
+		selector, ok := c.selectors[column.property]
+		if !ok {
+			selector = ConfigColumn{Names: []string{*column.name}}
+		}
+
+		index, ok := selector.Match(header)
+		if !ok && column.optional {
+			continue
+		}
+		if !ok {
+			missing = append(missing, fmt.Sprintf("column %s (%s)", column.property, selector))
+			continue
+		}
+		*column.name = header[index]
+	}
+
+	if len(missing) > 0 {
+		return resolved, &MissingColumnsError{Columns: missing, Header: header}
+	}
+
+	return resolved, nil
+}
+
+type inputColumn struct {
+	property string
+	name     *string
+	optional bool
+}
+
+func (c *ConfigColumns) inputColumns() []inputColumn {
+	return []inputColumn{
+		{"equipment_layer", &c.EquipmentLayer, false},
+		{"equipment_part_number", &c.EquipmentPartNumber, false},
+		{"equipment_id", &c.EquipmentID, false},
+		{"equipment_count_actual", &c.EquipmentCountActual, true},
+		{"equipment_count_target", &c.EquipmentCountTarget, false},
+		{"equipment_description", &c.EquipmentDescription, false},
+		{"equipment_unit", &c.EquipmentUnit, false},
+	}
+}
+
+func (c *ConfigColumns) UnmarshalJSON(data []byte) error {
+	var columns struct {
+		EquipmentLayer           ConfigColumn `json:"equipment_layer"`
+		EquipmentPartNumber      ConfigColumn `json:"equipment_part_number"`
+		EquipmentID              ConfigColumn `json:"equipment_id"`
+		EquipmentCountActual     ConfigColumn `json:"equipment_count_actual"`
+		EquipmentCountTarget     ConfigColumn `json:"equipment_count_target"`
+		EquipmentDescription     ConfigColumn `json:"equipment_description"`
+		EquipmentUnit            ConfigColumn `json:"equipment_unit"`
+		EquipmentCountDifference string       `json:"equipment_count_difference"`
+		EquipmentStatus          string       `json:"equipment_status"`
+		EquipmentCondition       string       `json:"equipment_condition"`
+		EquipmentNote            string       `json:"equipment_note"`
+	}
+
+	err := json.Unmarshal(data, &columns)
+	if err != nil {
+		return err
+	}
+
+	*c = ConfigColumns{
+		EquipmentCountDifference: columns.EquipmentCountDifference,
+		EquipmentStatus:          columns.EquipmentStatus,
+		EquipmentCondition:       columns.EquipmentCondition,
+		EquipmentNote:            columns.EquipmentNote,
+		selectors: map[string]ConfigColumn{
+			"equipment_layer":        columns.EquipmentLayer,
+			"equipment_part_number":  columns.EquipmentPartNumber,
+			"equipment_id":           columns.EquipmentID,
+			"equipment_count_actual": columns.EquipmentCountActual,
+			"equipment_count_target": columns.EquipmentCountTarget,
+			"equipment_description":  columns.EquipmentDescription,
+			"equipment_unit":         columns.EquipmentUnit,
+		},
+	}
+
+	for _, column := range c.inputColumns() {
+		*column.name = c.selectors[column.property].Name()
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package config_test
+
+import (
+	"encoding/json"
+	"thwInventoryMerge/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigColumns", func() {
+
+	header := []string{"Ebene ", "Ausstattung", "Sachnummer", "Inventar-Nr.", "Menge"}
+
+	unmarshal := func(jsonContent string) config.ConfigColumns {
+		var columns config.ConfigColumns
+		Expect(json.Unmarshal([]byte(jsonContent), &columns)).To(Succeed())
+		return columns
+	}
+
+	It("should resolve aliases, patterns and indexes to the header names", func() {
+		columns := unmarshal(`{
+			"equipment_layer": {"pattern": "^Ebene"},
+			"equipment_part_number": 3,
+			"equipment_id": ["Inventar Nr", "Inventar-Nr."],
+			"equipment_count_actual": "Bestand IST",
+			"equipment_count_target": "Menge",
+			"equipment_status": "Inventurstatus"
+		}`)
+
+		Expect(columns.EquipmentID).To(Equal("Inventar Nr"))
+		Expect(columns.EquipmentCountActual).To(Equal("Bestand IST"))
+
+		resolved, err := columns.Resolve(header)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resolved.EquipmentLayer).To(Equal("Ebene "))
+		Expect(resolved.EquipmentPartNumber).To(Equal("Sachnummer"))
+		Expect(resolved.EquipmentID).To(Equal("Inventar-Nr."))
+		Expect(resolved.EquipmentCountTarget).To(Equal("Menge"))
+		Expect(resolved.EquipmentStatus).To(Equal("Inventurstatus"))
+
+		// the actual column is added by the init step
+		Expect(resolved.EquipmentCountActual).To(Equal("Bestand IST"))
+	})
+
+	It("should list the available headers if a column does not match", func() {
+		columns := unmarshal(`{
+			"equipment_layer": "Ebene",
+			"equipment_part_number": "Sachnummer",
+			"equipment_id": ["Inventar Nr", "Inventarnummer"],
+			"equipment_count_actual": "Bestand IST",
+			"equipment_unit": 9
+		}`)
+
+		_, err := columns.Resolve(header)
+		Expect(err).To(MatchError("column equipment_id ('Inventar Nr' or 'Inventarnummer'), column equipment_unit (index 9) not found, " +
+			"available columns: 'Ebene ', 'Ausstattung', 'Sachnummer', 'Inventar-Nr.', 'Menge'"))
+	})
+
+	It("should match names regardless of BOM, white space, Unicode normalization and case", func() {
+		columns := config.ConfigColumns{
+			EquipmentLayer:       "Ebene",
+			EquipmentID:          "Inventar Nr",
+			EquipmentCountTarget: "Menge",
+			EquipmentUnit:        "Stück",
+		}
+
+		resolved, err := columns.Resolve([]string{"\ufeffEbene", "Inventar\u00a0 Nr ", "MENGE", "Stu\u0308ck"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resolved.EquipmentLayer).To(Equal("\ufeffEbene"))
+		Expect(resolved.EquipmentID).To(Equal("Inventar\u00a0 Nr "))
+		Expect(resolved.EquipmentCountTarget).To(Equal("MENGE"))
+		Expect(resolved.EquipmentUnit).To(Equal("Stu\u0308ck"))
+	})
+
+	It("should reject invalid columns", func() {
+		var columns config.ConfigColumns
+		Expect(json.Unmarshal([]byte(`{"equipment_id": {"pattern": "("}}`), &columns)).ToNot(Succeed())
+		Expect(json.Unmarshal([]byte(`{"equipment_id": 0}`), &columns)).ToNot(Succeed())
+		Expect(json.Unmarshal([]byte(`{"equipment_id": []}`), &columns)).ToNot(Succeed())
+		Expect(json.Unmarshal([]byte(`{"equipment_id": true}`), &columns)).ToNot(Succeed())
+	})
+})
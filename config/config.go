@@ -6,26 +6,167 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"thwInventoryMerge/utils"
+	"unicode"
+)
+
+const (
+	CountDistributionFillInOrder  = "fill_in_order"
+	CountDistributionProportional = "proportional"
+	CountDistributionDeepestLayer = "deepest_layer"
+)
+
+const (
+	PartNumberNormalizationNone         = "none"
+	PartNumberNormalizationUpper        = "upper"
+	PartNumberNormalizationAlphanumeric = "alphanumeric"
+)
+
+const (
+	TargetPolicyUnlimited = "unlimited"
+	TargetPolicySkip      = "skip"
+	TargetPolicyError     = "error"
+)
+
+const (
+	// DuplicateScanPolicySurplus counts the scans of unique equipment exceeding the target as surplus
+	DuplicateScanPolicySurplus = "surplus"
+
+	// DuplicateScanPolicyCap counts unique equipment scanned in several files only once
+	DuplicateScanPolicyCap = "cap"
+)
+
+const (
+	ReportOrderID     = "id"
+	ReportOrderFile   = "file"
+	ReportOrderAmount = "amount"
+)
+
+const (
+	OutputEncodingUTF8      = "utf-8"
+	OutputEncodingISO8859_1 = "iso-8859-1"
+
+	// OutputEncodingInput writes the outputs in the detected encoding of the inventory file
+	OutputEncodingInput = "input"
 )
 
 type Config struct {
 	WorkingDir           string        `json:"working_dir"`
 	InventoryCSVFileName string        `json:"inventory_csv_file_name"`
+	CountDistribution    string        `json:"count_distribution"`
+	BaselineResult       string        `json:"baseline_result"`
+	MigrationExport      string        `json:"migration_export"`
+	Database             string        `json:"database"`
+	TargetPolicy         string        `json:"target_policy"`
+	DuplicateScanPolicy  string        `json:"duplicate_scan_policy"`
+	ReportOrder          string        `json:"report_order"`
+	OutputEncoding       string        `json:"output_encoding"`
 	Columns              ConfigColumns `json:"columns"`
 
+	// scheme of the IDs generated for equipment without inventory number
+	PseudoID ConfigPseudoID `json:"pseudo_id"`
+
+	// rules applied in order to scans and inventory IDs before matching, scans are lowercased if empty
+	ScanNormalization []ConfigNormalizationRule `json:"scan_normalization"`
+
+	// formats of the scanned IDs, scans are not checked if empty
+	IDFormats []ConfigIDFormat `json:"id_formats"`
+
+	// column template of the THWin import, a default is used if empty
+	THWinExportColumns []ConfigExportColumn `json:"thwin_export_columns"`
+
 	logger utils.Logger
 }
 
+type ConfigExportColumn struct {
+	Header string `json:"header"`
+	Value  string `json:"value"`
+}
+
+type ConfigPseudoID struct {
+	// text/template of the ID, a default is used if empty
+	Template                string `json:"template"`
+	Separator               string `json:"separator"`
+	Prefix                  string `json:"prefix"`
+	PartNumberNormalization string `json:"part_number_normalization"`
+
+	// append an ordinal to IDs which would be used by several lines otherwise
+	OrdinalSuffix bool `json:"ordinal_suffix"`
+
+	// file pinning the generated IDs across THWin exports, not used if empty
+	MappingFile string `json:"mapping_file"`
+}
+
+var (
+	pseudoIDPrefixField    = regexp.MustCompile(`\{\{-?\s*\.Prefix\s*-?\}\}`)
+	pseudoIDSeparatorField = regexp.MustCompile(`\{\{-?\s*\.Separator\s*-?\}\}`)
+
+	// characters of inventory numbers like 0591-S00001
+	inventoryNumberCharacters = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+)
+
+func (p ConfigPseudoID) validate() error {
+	if p.Template != "" && !pseudoIDPrefixField.MatchString(p.Template) {
+		return fmt.Errorf("property pseudo_id.template must contain {{.Prefix}}")
+	}
+	if p.Template != "" && !pseudoIDSeparatorField.MatchString(p.Template) {
+		return fmt.Errorf("property pseudo_id.template must contain {{.Separator}}")
+	}
+
+	// inventory numbers start with a digit, without a prefix ruling that out the separator alone
+	// distinguishes pseudo IDs from them
+	prefixed := p.Prefix != "" && !unicode.IsDigit([]rune(p.Prefix)[0])
+	if !prefixed && inventoryNumberCharacters.MatchString(p.Separator) {
+		return fmt.Errorf("property pseudo_id.separator has invalid value '%s', it may occur in inventory numbers", p.Separator)
+	}
+
+	return nil
+}
+
 type ConfigColumns struct {
 	EquipmentLayer       string `json:"equipment_layer"`
 	EquipmentPartNumber  string `json:"equipment_part_number"`
 	EquipmentID          string `json:"equipment_id"`
 	EquipmentCountActual string `json:"equipment_count_actual"`
 	EquipmentCountTarget string `json:"equipment_count_target"`
+	EquipmentDescription string `json:"equipment_description"`
+	EquipmentUnit        string `json:"equipment_unit"`
+
+	// optional computed columns added to the result
+	EquipmentCountDifference string `json:"equipment_count_difference"`
+	EquipmentStatus          string `json:"equipment_status"`
+
+	// columns of the condition and notes set by command barcodes, defaults are used if empty
+	EquipmentCondition string `json:"equipment_condition"`
+	EquipmentNote      string `json:"equipment_note"`
+
+	// selectors of the columns read from the config, see Resolve
+	selectors map[string]ConfigColumn
 }
 
 func (c *Config) GetCSVFilesWithRecordedEquipment() ([]string, error) {
+	csvFiles, err := c.ListCSVFilesWithRecordedEquipment()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range csvFiles {
+		if i == 0 {
+			c.logger.Info("files with recorded equipment:")
+			c.logger.Info("")
+		}
+
+		c.logger.InfoIndented(fmt.Sprintf("using '%s'", filepath.Base(file)))
+	}
+
+	c.logger.Info("")
+
+	return csvFiles, nil
+}
+
+// ListCSVFilesWithRecordedEquipment returns the same files as GetCSVFilesWithRecordedEquipment without logging them
+func (c *Config) ListCSVFilesWithRecordedEquipment() ([]string, error) {
 	var csvFiles []string
 
 	files, err := os.ReadDir(c.WorkingDir)
@@ -33,26 +174,15 @@ func (c *Config) GetCSVFilesWithRecordedEquipment() ([]string, error) {
 		return nil, err
 	}
 
-	firstEquipment := true
-
 	for _, file := range files {
 		if !file.IsDir() &&
 			filepath.Ext(file.Name()) == ".csv" &&
 			filepath.Base(file.Name()) != c.InventoryCSVFileName {
 
-			if firstEquipment {
-				c.logger.Info("files with recorded equipment:")
-				c.logger.Info("")
-				firstEquipment = false
-			}
-
-			c.logger.InfoIndented(fmt.Sprintf("using '%s'", file.Name()))
 			csvFiles = append(csvFiles, filepath.Join(c.WorkingDir, file.Name()))
 		}
 	}
 
-	c.logger.Info("")
-
 	return csvFiles, nil
 }
 
@@ -60,6 +190,26 @@ func (c *Config) GetAbsoluteInventoryCSVFileName() string {
 	return filepath.Join(c.WorkingDir, c.InventoryCSVFileName)
 }
 
+// GetAbsoluteDatabaseFileName returns an empty string if no database is configured
+func (c *Config) GetAbsoluteDatabaseFileName() string {
+	if c.Database == "" || filepath.IsAbs(c.Database) {
+		return c.Database
+	}
+	return filepath.Join(c.WorkingDir, c.Database)
+}
+
+// GetAbsolutePseudoIDMappingFileName returns an empty string if no mapping file is configured
+func (c *Config) GetAbsolutePseudoIDMappingFileName() string {
+	if c.PseudoID.MappingFile == "" || filepath.IsAbs(c.PseudoID.MappingFile) {
+		return c.PseudoID.MappingFile
+	}
+	return filepath.Join(c.WorkingDir, c.PseudoID.MappingFile)
+}
+
+func (c *Config) GetResultDir() string {
+	return filepath.Join(c.WorkingDir, "result")
+}
+
 func LoadConfig(filePath string, logger utils.Logger) (*Config, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -99,5 +249,62 @@ func (c Config) validate() error {
 	if c.Columns.EquipmentCountActual == "" {
 		return errors.New("property columns.equipment_count_actual is required")
 	}
+	if selector := c.Columns.selectors["equipment_count_actual"]; selector.Index > 0 || selector.Pattern != nil {
+		return errors.New("property columns.equipment_count_actual must be a name or a list of names, the column is added by init if missing")
+	}
+	if c.Columns.EquipmentCountDifference != "" && c.Columns.EquipmentCountTarget == "" {
+		return errors.New("property columns.equipment_count_difference requires columns.equipment_count_target")
+	}
+	if c.Columns.EquipmentStatus != "" && c.Columns.EquipmentCountTarget == "" {
+		return errors.New("property columns.equipment_status requires columns.equipment_count_target")
+	}
+	for i, rule := range c.ScanNormalization {
+		if err := rule.validate(fmt.Sprintf("scan_normalization[%d]", i)); err != nil {
+			return err
+		}
+	}
+	for i, format := range c.IDFormats {
+		if err := format.validate(fmt.Sprintf("id_formats[%d]", i)); err != nil {
+			return err
+		}
+	}
+	for i, column := range c.THWinExportColumns {
+		if column.Header == "" {
+			return fmt.Errorf("property thwin_export_columns[%d].header is required", i)
+		}
+	}
+	switch c.CountDistribution {
+	case "", CountDistributionFillInOrder, CountDistributionProportional, CountDistributionDeepestLayer:
+	default:
+		return fmt.Errorf("property count_distribution has invalid value '%s'", c.CountDistribution)
+	}
+	switch c.PseudoID.PartNumberNormalization {
+	case "", PartNumberNormalizationNone, PartNumberNormalizationUpper, PartNumberNormalizationAlphanumeric:
+	default:
+		return fmt.Errorf("property pseudo_id.part_number_normalization has invalid value '%s'", c.PseudoID.PartNumberNormalization)
+	}
+	if err := c.PseudoID.validate(); err != nil {
+		return err
+	}
+	switch c.TargetPolicy {
+	case "", TargetPolicyUnlimited, TargetPolicySkip, TargetPolicyError:
+	default:
+		return fmt.Errorf("property target_policy has invalid value '%s'", c.TargetPolicy)
+	}
+	switch c.DuplicateScanPolicy {
+	case "", DuplicateScanPolicySurplus, DuplicateScanPolicyCap:
+	default:
+		return fmt.Errorf("property duplicate_scan_policy has invalid value '%s'", c.DuplicateScanPolicy)
+	}
+	switch c.ReportOrder {
+	case "", ReportOrderID, ReportOrderFile, ReportOrderAmount:
+	default:
+		return fmt.Errorf("property report_order has invalid value '%s'", c.ReportOrder)
+	}
+	switch c.OutputEncoding {
+	case "", OutputEncodingUTF8, OutputEncodingISO8859_1, OutputEncodingInput:
+	default:
+		return fmt.Errorf("property output_encoding has invalid value '%s'", c.OutputEncoding)
+	}
 	return nil
 }
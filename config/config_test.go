@@ -151,6 +151,264 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(Equal("failed to validate the config file, property columns.equipment_count_actual is required"))
 			Expect(cfg).To(BeNil())
 		})
+
+		It("returns an error if columns.equipment_count_actual is selected by index or pattern", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": {"pattern": "^Bestand"}
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property columns.equipment_count_actual must be a name or a list of names, the column is added by init if missing"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if count_distribution is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"count_distribution": "random",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property count_distribution has invalid value 'random'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if target_policy is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"target_policy": "guess",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property target_policy has invalid value 'guess'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if report_order is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"report_order": "random",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property report_order has invalid value 'random'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if a scan_normalization rule is invalid", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"scan_normalization": [{"type": "trim"}, {"type": "replace", "pattern": "("}],
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property scan_normalization[1].pattern is invalid: error parsing regexp: missing closing ): `(`"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if an id_format has no pattern", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"id_formats": [{"name": "inventory_number"}],
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property id_formats[0].pattern is required"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if duplicate_scan_policy is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"duplicate_scan_policy": "random",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property duplicate_scan_policy has invalid value 'random'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if output_encoding is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"output_encoding": "utf-16",
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property output_encoding has invalid value 'utf-16'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if pseudo_id.part_number_normalization is unknown", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"pseudo_id": {
+				"part_number_normalization": "lower"
+			},
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property pseudo_id.part_number_normalization has invalid value 'lower'"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if pseudo_id.separator may occur in inventory numbers without prefix", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"pseudo_id": {
+				"separator": "-"
+			},
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property pseudo_id.separator has invalid value '-', it may occur in inventory numbers"))
+			Expect(cfg).To(BeNil())
+		})
+
+		It("returns an error if pseudo_id.template lacks the separator", func() {
+			jsonContent := `
+		{
+			"inventory_csv_file_name": "foo_inventory_csv_file_name",
+			"pseudo_id": {
+				"template": "{{.Prefix}}{{.ParentID}}-{{.PartNumber}}"
+			},
+			"columns": {
+				"equipment_layer": "foo_equipment_layer_column_name",
+				"equipment_part_number": "foo_equipment_part_number_column_name",
+				"equipment_id": "foo_equipment_id",
+				"equipment_count_actual": "foo_equipment_available_column_name"
+			}
+		}
+		`
+			_, err := tempFile.Write([]byte(jsonContent))
+			Expect(err).ToNot(HaveOccurred())
+			tempFile.Close()
+
+			cfg, err := config.LoadConfig(tempFile.Name(), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to validate the config file, property pseudo_id.template must contain {{.Separator}}"))
+			Expect(cfg).To(BeNil())
+		})
 	})
 
 	var _ = Describe("GetCSVFilesWithRecordedEquipment", func() {
@@ -200,4 +458,29 @@ var _ = Describe("Config", func() {
 		})
 	})
 
+	var _ = Describe("ListCSVFilesWithRecordedEquipment", func() {
+		It("should return the CSV files without logging them", func() {
+			tempDir, err := os.MkdirTemp("", "test-csv")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+
+			for _, fileName := range []string{"file1.csv", "inventory_fgr_n.csv", "notes.txt"} {
+				file, err := os.Create(filepath.Join(tempDir, fileName))
+				Expect(err).ToNot(HaveOccurred())
+				file.Close()
+			}
+			Expect(os.Mkdir(filepath.Join(tempDir, "result"), 0755)).To(Succeed())
+
+			// without a logger any logging would panic
+			cfg := config.Config{
+				WorkingDir:           tempDir,
+				InventoryCSVFileName: "inventory_fgr_n.csv",
+			}
+
+			files, err := cfg.ListCSVFilesWithRecordedEquipment()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(Equal([]string{filepath.Join(tempDir, "file1.csv")}))
+		})
+	})
+
 })
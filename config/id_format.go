@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ConfigIDFormat is the format of a type of IDs like inventory numbers, part numbers or pseudo IDs.
+// A scan matching Candidate but not Pattern is a misread of this type.
+type ConfigIDFormat struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`
+	Candidate string `json:"candidate"`
+}
+
+func (f ConfigIDFormat) validate(property string) error {
+	if f.Name == "" {
+		return fmt.Errorf("property %s.name is required", property)
+	}
+	if f.Pattern == "" {
+		return fmt.Errorf("property %s.pattern is required", property)
+	}
+	if _, err := regexp.Compile(f.Pattern); err != nil {
+		return fmt.Errorf("property %s.pattern is invalid: %w", property, err)
+	}
+	if _, err := regexp.Compile(f.Candidate); err != nil {
+		return fmt.Errorf("property %s.candidate is invalid: %w", property, err)
+	}
+	return nil
+}
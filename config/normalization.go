@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	NormalizationTrim        = "trim"
+	NormalizationStripPrefix = "strip_prefix"
+	NormalizationStripSuffix = "strip_suffix"
+	NormalizationReplace     = "replace"
+	NormalizationPadZeros    = "pad_zeros"
+	NormalizationLower       = "lower"
+	NormalizationUpper       = "upper"
+)
+
+// ConfigNormalizationRule is a step of the normalization applied to scans and inventory IDs before matching
+type ConfigNormalizationRule struct {
+	Type string `json:"type"`
+
+	// Value is the prefix or suffix to strip
+	Value string `json:"value"`
+
+	// Pattern is the regular expression replaced by Replacement, which may refer to groups like ${1}
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+
+	// Width is the number of digits the last number of the value is padded to with leading zeros
+	Width int `json:"width"`
+}
+
+func (r ConfigNormalizationRule) validate(property string) error {
+	switch r.Type {
+	case NormalizationTrim, NormalizationLower, NormalizationUpper:
+	case NormalizationStripPrefix, NormalizationStripSuffix:
+		if r.Value == "" {
+			return fmt.Errorf("property %s.value is required", property)
+		}
+	case NormalizationReplace:
+		if r.Pattern == "" {
+			return fmt.Errorf("property %s.pattern is required", property)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("property %s.pattern is invalid: %w", property, err)
+		}
+	case NormalizationPadZeros:
+		if r.Width < 1 {
+			return fmt.Errorf("property %s.width has to be greater than 0", property)
+		}
+	default:
+		return fmt.Errorf("property %s.type has invalid value '%s'", property, r.Type)
+	}
+	return nil
+}
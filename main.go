@@ -1,9 +1,8 @@
 package main
 
 import (
-	"flag"
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"thwInventoryMerge/app"
@@ -13,60 +12,61 @@ import (
 
 func main() {
 	
-	logger := utils.NewLogger()
+	logger := utils.NewWarningCounter(utils.NewLogger())
+	registry := app.NewDefaultStepRegistry()
 
-	var configPath string
-	var step string
-	
-	flag.StringVar(&configPath, "c", "config.json", "the config file path")
-	flag.StringVar(&step, "s", "process", "the inventory step")
-	flag.Parse()
+	program := filepath.Base(os.Args[0])
 
-	executablePath := getExecutablePath(logger)
-	
-	if configPath == "" {
-		configPath = filepath.Join(executablePath, "config.json")
+	commandLine, err := app.ParseCommandLine(registry, program, os.Args[1:], os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(app.ExitFailure)
+	}
+	if commandLine.Help {
+		os.Exit(app.ExitSuccess)
 	}
 
-	config, err := config.LoadConfig(configPath, logger)
+	err = run(commandLine, logger)
 	if err != nil {
-		log.Fatalf("Failed to load config from path %s: %v", configPath, err)
+		logger.Error(err.Error())
 	}
 
-	if config.WorkingDir == "" {
-		config.WorkingDir = executablePath
+	// Keep the terminal open when started by double click, scripts and scheduled jobs must not block
+	if !commandLine.NoPause && utils.IsTerminal(os.Stdin) {
+		fmt.Println("Press Enter to exit...")
+		fmt.Scanln()
 	}
 
-	switch step {
-	case "init":
-			fmt.Println("Running initialization step")
-			err := app.NewInitInventoryCSVStep(*config, logger).Init()
+	os.Exit(app.ExitCode(err, logger.Warnings()))
+}
+
+func run(commandLine app.CommandLine, logger utils.Logger) error {
+	executablePath, err := getExecutablePath()
+	if err != nil {
+		return err
+	}
 
-			if err != nil {
-				logger.Fatal(fmt.Sprintf("Failed to init inventory csv: %v", err))
-			}
+	configPath := commandLine.ConfigPath
+	if configPath == "" {
+		configPath = filepath.Join(executablePath, "config.json")
+	}
 
-	case "process":
-			fmt.Println("Running inventory step")
-			err := app.NewProcessInvetoryStep(*config, logger).Process()
+	config, err := config.LoadConfig(configPath, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load config from path %s: %w", configPath, err)
+	}
 
-			if err != nil {
-				logger.Fatal(fmt.Sprintf("Failed to process inventory: %v", err))
-			}
-			
-	default:
-			logger.Fatal(fmt.Sprintf("Invalid step: %s", step))
+	if config.WorkingDir == "" {
+		config.WorkingDir = executablePath
 	}
 
-	// Keep the terminal open
-	fmt.Println("Press Enter to exit...")
-	fmt.Scanln()
+	return app.RunSteps(context.Background(), commandLine.Steps, commandLine.FlagValues, *config, logger)
 }
 
-func getExecutablePath(logger utils.Logger) string {
+func getExecutablePath() (string, error) {
 	exePath, err := os.Executable()
 	if err != nil {
-		logger.Fatal(fmt.Sprintf("Failed to get executable path: %v", err))
+		return "", fmt.Errorf("failed to get executable path: %w", err)
 	}
-	return filepath.Dir(exePath)   
-}
\ No newline at end of file
+	return filepath.Dir(exePath), nil
+}
@@ -2,7 +2,11 @@ package utils
 
 import (
 	"strconv"
+	"strings"
 	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
 func StartsWithNumber(s string) bool {
@@ -15,4 +19,22 @@ func StartsWithNumber(s string) bool {
 func IsNumber(s string) bool {
 	_, err := strconv.Atoi(s)
 	return err == nil
-}
\ No newline at end of file
+}
+
+// CleanHeader removes a BOM as well as surrounding and repeated white space incl. non-breaking
+// spaces from a column header and applies the Unicode normalization form NFC
+func CleanHeader(s string) string {
+	s = strings.ReplaceAll(s, "\ufeff", "")
+	s = strings.Join(strings.Fields(s), " ")
+	return norm.NFC.String(s)
+}
+
+// NormalizeHeader returns the key a column header is looked up by, the cleaned header without case
+func NormalizeHeader(s string) string {
+	return cases.Fold().String(CleanHeader(s))
+}
+
+// EqualHeaders reports whether both column headers have the same normalized key
+func EqualHeaders(a, b string) bool {
+	return NormalizeHeader(a) == NormalizeHeader(b)
+}
@@ -0,0 +1,21 @@
+package utils_test
+
+import (
+	"thwInventoryMerge/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NormalizeHeader", func() {
+	It("should ignore BOM, white space, Unicode normalization and case", func() {
+		Expect(utils.NormalizeHeader("\ufeffEbene")).To(Equal("ebene"))
+		Expect(utils.NormalizeHeader(" Inventar\u00a0 Nr ")).To(Equal("inventar nr"))
+		Expect(utils.NormalizeHeader("Bestand Stu\u0308ck")).To(Equal("bestand stück"))
+		Expect(utils.EqualHeaders("MENGE", "Menge")).To(BeTrue())
+	})
+
+	It("should keep the case of a cleaned header", func() {
+		Expect(utils.CleanHeader("\ufeff Inventar\u00a0Nr ")).To(Equal("Inventar Nr"))
+	})
+})
@@ -0,0 +1,14 @@
+//go:build !windows && !linux
+
+package utils
+
+import "os"
+
+// IsTerminal returns false if the file is redirected, e.g. when running as scheduled job
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsTerminal returns false if the file is no console, e.g. when running as scheduled job
+func IsTerminal(file *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(file.Fd()), &mode) == nil
+}
@@ -0,0 +1,33 @@
+package utils
+
+// WarningCounter is a Logger which counts the logged warnings
+type WarningCounter interface {
+	Logger
+
+	Warnings() int
+}
+
+type warningCounter struct {
+	Logger
+	warnings int
+}
+
+func NewWarningCounter(logger Logger) WarningCounter {
+	return &warningCounter{
+		Logger: logger,
+	}
+}
+
+func (w *warningCounter) Warn(message string) {
+	w.warnings++
+	w.Logger.Warn(message)
+}
+
+func (w *warningCounter) WarnIndented(message string) {
+	w.warnings++
+	w.Logger.WarnIndented(message)
+}
+
+func (w *warningCounter) Warnings() int {
+	return w.warnings
+}
@@ -0,0 +1,25 @@
+package utils_test
+
+import (
+	"thwInventoryMerge/utils"
+	"thwInventoryMerge/utils/utilsfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WarningCounter", func() {
+	It("should count the warnings and pass all messages on", func() {
+		logger := &utilsfakes.FakeLogger{}
+		counter := utils.NewWarningCounter(logger)
+
+		counter.Info("info")
+		counter.Warn("warning")
+		counter.WarnIndented("detail")
+
+		Expect(counter.Warnings()).To(Equal(2))
+		Expect(logger.InfoArgsForCall(0)).To(Equal("info"))
+		Expect(logger.WarnArgsForCall(0)).To(Equal("warning"))
+		Expect(logger.WarnIndentedArgsForCall(0)).To(Equal("detail"))
+	})
+})